@@ -0,0 +1,128 @@
+// Command simulate plays bot-vs-bot games and prints balance statistics.
+//
+//	go run ./cmd/simulate -games 2000 -players 3 -preset quick
+//	go run ./cmd/simulate -rules '{"goldCount":3}' -bots easy,normal -json
+//	go run ./cmd/simulate -cards cards.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"splendor/backend/internal/game"
+	"splendor/backend/internal/sim"
+)
+
+func main() {
+	games := flag.Int("games", 1000, "number of games to play")
+	players := flag.Int("players", 2, "seats per game (2-4)")
+	seed := flag.Int64("seed", 1, "seed of the first game; game i uses seed+i")
+	preset := flag.String("preset", game.PresetStandard, "rule preset: standard, quick or long")
+	rules := flag.String("rules", "", `JSON rule overrides, e.g. '{"targetScore":12}'`)
+	mode := flag.String("mode", game.ModeStrict, "game mode: strict or sandbox")
+	cardsPath := flag.String("cards", "", "JSON file with a custom card set replacing the standard deck")
+	bots := flag.String("bots", "", "comma-separated difficulty per seat (easy, normal, hard); default normal")
+	workers := flag.Int("workers", 0, "games played in parallel; 0 means one per CPU")
+	top := flag.Int("top", 10, "number of most and least bought cards to list")
+	asJSON := flag.Bool("json", false, "print the raw statistics as JSON")
+	flag.Parse()
+	if *top < 0 {
+		log.Fatalf("invalid -top: must not be negative")
+	}
+
+	var overrides game.RuleOverrides
+	if *rules != "" {
+		if err := json.Unmarshal([]byte(*rules), &overrides); err != nil {
+			log.Fatalf("invalid -rules: %v", err)
+		}
+	}
+	ruleSet, err := game.ResolveRules(*preset, overrides)
+	if err != nil {
+		log.Fatalf("invalid rules: %v", err)
+	}
+	var cards []game.Card
+	if *cardsPath != "" {
+		data, err := os.ReadFile(*cardsPath)
+		if err != nil {
+			log.Fatalf("read -cards: %v", err)
+		}
+		if err := json.Unmarshal(data, &cards); err != nil {
+			log.Fatalf("invalid -cards: %v", err)
+		}
+	}
+	var difficulties []string
+	if *bots != "" {
+		difficulties = strings.Split(*bots, ",")
+	}
+
+	stats, err := sim.Run(sim.Config{
+		Games:        *games,
+		Players:      *players,
+		Seed:         *seed,
+		Rules:        ruleSet,
+		Mode:         *mode,
+		Cards:        cards,
+		Difficulties: difficulties,
+		Workers:      *workers,
+	})
+	if err != nil {
+		log.Fatalf("simulation failed: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(stats); err != nil {
+			log.Fatalf("encode stats: %v", err)
+		}
+		return
+	}
+	printStats(stats, *top)
+}
+
+func printStats(stats sim.Stats, top int) {
+	finished := stats.Games - stats.Stalled
+	fmt.Printf("games: %d (finished %d, stalled %d)\n", stats.Games, finished, stats.Stalled)
+	fmt.Printf("first player win rate: %.1f%%\n", 100*stats.FirstPlayerWinRate)
+	for seat, wins := range stats.SeatWins {
+		fmt.Printf("  seat %d wins: %d\n", seat+1, wins)
+	}
+	fmt.Printf("average length: %.1f turns (%.1f rounds)\n", stats.AverageTurns, stats.AverageRounds)
+	fmt.Printf("average winning points: %.1f\n", stats.AverageWinningPoints)
+
+	cards := make([]string, 0, len(stats.CardPurchases))
+	for id := range stats.CardPurchases {
+		cards = append(cards, id)
+	}
+	sort.Slice(cards, func(i, j int) bool {
+		a, b := stats.CardPurchases[cards[i]], stats.CardPurchases[cards[j]]
+		if a != b {
+			return a > b
+		}
+		return cards[i] < cards[j]
+	})
+	top = min(top, len(cards))
+	fmt.Println("most bought cards (purchases per finished game):")
+	for _, id := range cards[:top] {
+		fmt.Printf("  %-12s %.3f\n", id, float64(stats.CardPurchases[id])/float64(max(finished, 1)))
+	}
+	fmt.Println("least bought cards:")
+	for _, id := range cards[len(cards)-top:] {
+		fmt.Printf("  %-12s %.3f\n", id, float64(stats.CardPurchases[id])/float64(max(finished, 1)))
+	}
+
+	nobles := make([]string, 0, len(stats.NobleDealt))
+	for id := range stats.NobleDealt {
+		nobles = append(nobles, id)
+	}
+	sort.Strings(nobles)
+	fmt.Println("noble claim rates:")
+	for _, id := range nobles {
+		fmt.Printf("  %-4s %5.1f%% of %d games\n", id, 100*stats.NobleClaimRate(id), stats.NobleDealt[id])
+	}
+}
@@ -4,26 +4,42 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 
+	"splendor/backend/internal/bot"
 	"splendor/backend/internal/game"
 	"splendor/backend/internal/lobby"
+	"splendor/backend/internal/matchmaking"
 	"splendor/backend/internal/ws"
 )
 
 type App struct {
 	store    *lobby.Store
+	queue    *matchmaking.Queue
 	hub      *ws.Hub
 	upgrader websocket.Upgrader
+
+	// listed holds the last room browser listing published for each room,
+	// kept after the room is unlisted so late snapshots can be told apart.
+	listedMu sync.Mutex
+	listed   map[string]lobbyListing
+
+	// thinking marks the rooms with a searching bot goroutine running.
+	botsMu   sync.Mutex
+	thinking map[string]bool
 }
 
 func New() *App {
 	app := &App{
-		store: lobby.NewStore(),
-		hub:   ws.NewHub(),
+		store:    lobby.NewStore(),
+		hub:      ws.NewHub(),
+		listed:   make(map[string]lobbyListing),
+		thinking: make(map[string]bool),
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
@@ -32,7 +48,9 @@ func New() *App {
 			},
 		},
 	}
+	app.queue = matchmaking.NewQueue(app.store, matchmaking.Config{})
 	app.startTimeoutLoop()
+	app.startMatchLoop()
 	return app
 }
 
@@ -42,6 +60,10 @@ func (a *App) Routes() http.Handler {
 	mux.HandleFunc("/api/rooms", a.handleRooms)
 	mux.HandleFunc("/api/rooms/", a.handleRoomByID)
 	mux.HandleFunc("/ws", a.handleWS)
+	mux.HandleFunc("/ws/lobby", a.handleLobbyWS)
+	mux.HandleFunc("/api/matchmaking/queue", a.handleEnqueue)
+	mux.HandleFunc("/api/matchmaking/queue/", a.handleTicket)
+	mux.HandleFunc("/ws/matchmaking", a.handleMatchmakingWS)
 	return withCORS(mux)
 }
 
@@ -53,8 +75,17 @@ func (a *App) handleHealth(w http.ResponseWriter, _ *http.Request) {
 }
 
 type createRoomRequest struct {
-	HostName    string `json:"hostName"`
-	TurnSeconds int    `json:"turnSeconds,omitempty"`
+	HostName      string              `json:"hostName"`
+	TurnSeconds   int                 `json:"turnSeconds,omitempty"`
+	RulePreset    string              `json:"rulePreset,omitempty"`
+	Rules         *game.RuleOverrides `json:"rules,omitempty"`
+	Mode          string              `json:"mode,omitempty"`
+	TimeoutPolicy lobby.TimeoutPolicy `json:"timeoutPolicy"`
+	LogLimit      int                 `json:"logLimit,omitempty"`
+	AutoStart     bool                `json:"autoStart,omitempty"`
+	MaxPlayers    int                 `json:"maxPlayers,omitempty"`
+	Visibility    string              `json:"visibility,omitempty"`
+	Password      string              `json:"password,omitempty"`
 }
 
 type createRoomResponse struct {
@@ -63,6 +94,10 @@ type createRoomResponse struct {
 }
 
 func (a *App) handleRooms(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		a.handleListRooms(w, r)
+		return
+	}
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
 		return
@@ -78,16 +113,38 @@ func (a *App) handleRooms(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	room, err := a.store.CreateRoom(req.HostName, req.TurnSeconds)
+	var overrides game.RuleOverrides
+	if req.Rules != nil {
+		overrides = *req.Rules
+	}
+	rules, err := game.ResolveRules(req.RulePreset, overrides)
 	if err != nil {
 		writeLobbyError(w, err)
 		return
 	}
+
+	room, err := a.store.CreateRoomWithOptions(req.HostName, lobby.RoomOptions{
+		TurnSeconds:   req.TurnSeconds,
+		Rules:         rules,
+		Mode:          req.Mode,
+		TimeoutPolicy: req.TimeoutPolicy,
+		LogLimit:      req.LogLimit,
+		AutoStart:     req.AutoStart,
+		MaxPlayers:    req.MaxPlayers,
+		Visibility:    req.Visibility,
+		Password:      req.Password,
+	})
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+	a.publishLobby(room)
 	writeJSON(w, http.StatusCreated, createRoomResponse{Room: room, Player: room.Players[0]})
 }
 
 type joinRoomRequest struct {
 	PlayerName string `json:"playerName"`
+	Password   string `json:"password,omitempty"`
 }
 
 type joinRoomResponse struct {
@@ -97,6 +154,43 @@ type joinRoomResponse struct {
 
 type startGameRequest struct {
 	PlayerID string `json:"playerId"`
+	Seed     *int64 `json:"seed,omitempty"`
+	Force    bool   `json:"force,omitempty"`
+}
+
+// settingsRequest changes the fields that are present. A rulePreset or rules
+// field resolves the rules afresh, as at room creation.
+type settingsRequest struct {
+	PlayerID      string               `json:"playerId"`
+	TurnSeconds   *int                 `json:"turnSeconds,omitempty"`
+	RulePreset    *string              `json:"rulePreset,omitempty"`
+	Rules         *game.RuleOverrides  `json:"rules,omitempty"`
+	MaxPlayers    *int                 `json:"maxPlayers,omitempty"`
+	Visibility    *string              `json:"visibility,omitempty"`
+	TimeoutPolicy *lobby.TimeoutPolicy `json:"timeoutPolicy,omitempty"`
+}
+
+type readyRequest struct {
+	PlayerID string `json:"playerId"`
+	Ready    bool   `json:"ready"`
+}
+
+type leaveRoomRequest struct {
+	PlayerID string `json:"playerId"`
+}
+
+// hostRequest is the body of the host-only seat management routes. TargetID
+// names the player to kick or make host; Order and Shuffle rearrange seats.
+type hostRequest struct {
+	PlayerID string   `json:"playerId"`
+	TargetID string   `json:"targetId,omitempty"`
+	Order    []string `json:"order,omitempty"`
+	Shuffle  bool     `json:"shuffle,omitempty"`
+}
+
+type addBotRequest struct {
+	PlayerID   string `json:"playerId"`
+	Difficulty string `json:"difficulty,omitempty"`
 }
 
 type actionRequest struct {
@@ -113,20 +207,43 @@ func (a *App) handleRoomByID(w http.ResponseWriter, r *http.Request) {
 
 	switch {
 	case resource == "" && r.Method == http.MethodGet:
-		a.handleGetRoom(w, roomID)
+		a.handleGetRoom(w, r, roomID)
 	case resource == "join" && r.Method == http.MethodPost:
 		a.handleJoinRoom(w, r, roomID)
+	case resource == "settings" && r.Method == http.MethodPatch:
+		a.handleUpdateSettings(w, r, roomID)
+	case resource == "ready" && r.Method == http.MethodPost:
+		a.handleReady(w, r, roomID)
+	case resource == "leave" && r.Method == http.MethodPost:
+		a.handleLeaveRoom(w, r, roomID)
+	case (resource == "kick" || resource == "host" || resource == "seats") && r.Method == http.MethodPost:
+		a.handleHostControl(w, r, roomID, resource)
+	case resource == "bots" && r.Method == http.MethodPost:
+		a.handleAddBot(w, r, roomID)
 	case resource == "start" && r.Method == http.MethodPost:
 		a.handleStartGame(w, r, roomID)
 	case resource == "state" && r.Method == http.MethodGet:
-		a.handleGameState(w, roomID)
+		a.handleGameState(w, r, roomID)
 	case resource == "actions" && r.Method == http.MethodPost:
 		a.handleAction(w, r, roomID)
+	case resource == "legal-actions" && r.Method == http.MethodGet:
+		a.handleLegalActions(w, r, roomID)
+	case resource == "preview" && r.Method == http.MethodGet:
+		a.handlePreview(w, r, roomID)
+	case resource == "log" && r.Method == http.MethodGet:
+		a.handleGameLog(w, r, roomID)
 	default:
 		writeError(w, http.StatusNotFound, "route_not_found", "route not found")
 	}
 }
 
+// viewerID is the optional playerId query parameter used to decide which
+// hidden information a read request may see. Player IDs are in every room
+// snapshot, so this only hides cards from honest clients.
+func viewerID(r *http.Request) string {
+	return strings.TrimSpace(r.URL.Query().Get("playerId"))
+}
+
 func parseRoomPath(path string) (roomID string, resource string) {
 	trimmed := strings.TrimPrefix(path, "/api/rooms/")
 	parts := strings.Split(strings.Trim(trimmed, "/"), "/")
@@ -141,13 +258,26 @@ func parseRoomPath(path string) (roomID string, resource string) {
 	return roomID, resource
 }
 
-func (a *App) handleGetRoom(w http.ResponseWriter, roomID string) {
+// admitted applies a room's password to read requests: in a protected room
+// only a seated playerId, or the password query parameter, gets through.
+func (a *App) admitted(w http.ResponseWriter, r *http.Request, roomID string) bool {
+	if err := a.store.CheckAccess(roomID, viewerID(r), r.URL.Query().Get("password")); err != nil {
+		writeLobbyError(w, err)
+		return false
+	}
+	return true
+}
+
+func (a *App) handleGetRoom(w http.ResponseWriter, r *http.Request, roomID string) {
+	if !a.admitted(w, r, roomID) {
+		return
+	}
 	room, err := a.store.GetRoom(roomID)
 	if err != nil {
 		writeLobbyError(w, err)
 		return
 	}
-	writeJSON(w, http.StatusOK, room)
+	writeJSON(w, http.StatusOK, room.RedactedFor(viewerID(r)))
 }
 
 func (a *App) handleJoinRoom(w http.ResponseWriter, r *http.Request, roomID string) {
@@ -161,14 +291,178 @@ func (a *App) handleJoinRoom(w http.ResponseWriter, r *http.Request, roomID stri
 		return
 	}
 
-	room, player, err := a.store.JoinRoom(roomID, req.PlayerName)
+	room, player, err := a.store.JoinRoomWithPassword(roomID, req.PlayerName, req.Password)
 	if err != nil {
 		writeLobbyError(w, err)
 		return
 	}
 
 	a.broadcastRoomSnapshotRefs(room, "player_joined")
-	writeJSON(w, http.StatusOK, joinRoomResponse{Room: room, Player: player})
+	writeJSON(w, http.StatusOK, joinRoomResponse{Room: room.RedactedFor(player.ID), Player: player})
+}
+
+func (a *App) handleLeaveRoom(w http.ResponseWriter, r *http.Request, roomID string) {
+	var req leaveRoomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerID) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+
+	update, err := a.store.LeaveRoom(roomID, req.PlayerID)
+	if err != nil {
+		a.broadcastIfQuarantined(roomID, err)
+		writeDomainError(w, err)
+		return
+	}
+
+	reason := "player_left"
+	switch {
+	case update.Closed:
+		reason = "room_closed"
+	case update.HostChanged:
+		reason = "host_left"
+	}
+	a.broadcastRoomEvents(update.Room, reason, update.Events)
+	writeJSON(w, http.StatusOK, update.Room.RedactedFor(req.PlayerID))
+}
+
+func (a *App) handleHostControl(w http.ResponseWriter, r *http.Request, roomID, resource string) {
+	var req hostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerID) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+
+	var room *lobby.Room
+	var reason string
+	var err error
+	switch {
+	case resource == "kick":
+		room, err = a.store.KickPlayer(roomID, req.PlayerID, req.TargetID)
+		reason = "player_kicked"
+		if err == nil {
+			a.disconnectPlayer(room, strings.TrimSpace(req.TargetID), "kicked")
+		}
+	case resource == "host":
+		room, err = a.store.TransferHost(roomID, req.PlayerID, req.TargetID)
+		reason = "host_transferred"
+	case req.Shuffle:
+		room, err = a.store.ShuffleSeats(roomID, req.PlayerID)
+		reason = "seats_reordered"
+	default:
+		room, err = a.store.SetSeatOrder(roomID, req.PlayerID, req.Order)
+		reason = "seats_reordered"
+	}
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+
+	a.broadcastRoomSnapshotRefs(room, reason)
+	writeJSON(w, http.StatusOK, room.RedactedFor(req.PlayerID))
+}
+
+func (a *App) handleAddBot(w http.ResponseWriter, r *http.Request, roomID string) {
+	var req addBotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerID) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+
+	room, player, err := a.store.AddBot(roomID, req.PlayerID, req.Difficulty)
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+
+	a.broadcastRoomSnapshotRefs(room, startedOr(room, "bot_added"))
+	writeJSON(w, http.StatusOK, joinRoomResponse{Room: room.RedactedFor(req.PlayerID), Player: player})
+}
+
+func (a *App) handleUpdateSettings(w http.ResponseWriter, r *http.Request, roomID string) {
+	var req settingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerID) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+
+	update := lobby.SettingsUpdate{
+		TurnSeconds:   req.TurnSeconds,
+		MaxPlayers:    req.MaxPlayers,
+		Visibility:    req.Visibility,
+		TimeoutPolicy: req.TimeoutPolicy,
+	}
+	// A new preset is resolved like at creation; rules alone adjust the
+	// room's current rules.
+	if req.RulePreset != nil {
+		var overrides game.RuleOverrides
+		if req.Rules != nil {
+			overrides = *req.Rules
+		}
+		rules, err := game.ResolveRules(*req.RulePreset, overrides)
+		if err != nil {
+			writeLobbyError(w, err)
+			return
+		}
+		update.Rules = &rules
+	} else {
+		update.RuleOverrides = req.Rules
+	}
+
+	room, err := a.store.UpdateSettings(roomID, req.PlayerID, update)
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+
+	a.broadcastRoomSnapshotRefs(room, startedOr(room, "settings_changed"))
+	writeJSON(w, http.StatusOK, room.RedactedFor(req.PlayerID))
+}
+
+func (a *App) handleReady(w http.ResponseWriter, r *http.Request, roomID string) {
+	var req readyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerID) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+
+	room, err := a.store.SetReady(roomID, req.PlayerID, req.Ready)
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+
+	a.broadcastRoomSnapshotRefs(room, startedOr(room, "player_ready"))
+	writeJSON(w, http.StatusOK, room.RedactedFor(req.PlayerID))
+}
+
+// startedOr returns the broadcast reason for a waiting-room change, which
+// is game_started when the change auto-started the game.
+func startedOr(room *lobby.Room, reason string) string {
+	if room.Status == lobby.RoomPlaying {
+		return "game_started"
+	}
+	return reason
 }
 
 func (a *App) handleStartGame(w http.ResponseWriter, r *http.Request, roomID string) {
@@ -182,17 +476,20 @@ func (a *App) handleStartGame(w http.ResponseWriter, r *http.Request, roomID str
 		return
 	}
 
-	room, err := a.store.StartGame(roomID, req.PlayerID)
+	room, err := a.store.StartGame(roomID, req.PlayerID, lobby.StartOptions{Seed: req.Seed, Force: req.Force})
 	if err != nil {
 		writeLobbyError(w, err)
 		return
 	}
 
 	a.broadcastRoomSnapshotRefs(room, "game_started")
-	writeJSON(w, http.StatusOK, room)
+	writeJSON(w, http.StatusOK, room.RedactedFor(req.PlayerID))
 }
 
-func (a *App) handleGameState(w http.ResponseWriter, roomID string) {
+func (a *App) handleGameState(w http.ResponseWriter, r *http.Request, roomID string) {
+	if !a.admitted(w, r, roomID) {
+		return
+	}
 	room, err := a.store.GetRoom(roomID)
 	if err != nil {
 		writeLobbyError(w, err)
@@ -202,7 +499,7 @@ func (a *App) handleGameState(w http.ResponseWriter, roomID string) {
 		writeError(w, http.StatusConflict, "game_not_started", "game not started")
 		return
 	}
-	writeJSON(w, http.StatusOK, room.Game)
+	writeJSON(w, http.StatusOK, room.Game.RedactedFor(viewerID(r)))
 }
 
 func (a *App) handleAction(w http.ResponseWriter, r *http.Request, roomID string) {
@@ -216,24 +513,98 @@ func (a *App) handleAction(w http.ResponseWriter, r *http.Request, roomID string
 		return
 	}
 
-	room, err := a.store.ApplyAction(roomID, req.PlayerID, req.Action)
+	room, events, err := a.store.ApplyAction(roomID, req.PlayerID, req.Action)
+	if err != nil {
+		a.broadcastIfQuarantined(roomID, err)
+		writeDomainError(w, err)
+		return
+	}
+
+	a.broadcastRoomEvents(room, "action_applied", events)
+	writeJSON(w, http.StatusOK, room.RedactedFor(req.PlayerID))
+}
+
+type legalActionsResponse struct {
+	PlayerID string        `json:"playerId"`
+	Actions  []game.Action `json:"actions"`
+}
+
+func (a *App) handleLegalActions(w http.ResponseWriter, r *http.Request, roomID string) {
+	playerID := strings.TrimSpace(r.URL.Query().Get("playerId"))
+	if playerID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+	if !a.admitted(w, r, roomID) {
+		return
+	}
+
+	actions, err := a.store.LegalActions(roomID, playerID)
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, legalActionsResponse{PlayerID: playerID, Actions: actions})
+}
+
+func (a *App) handlePreview(w http.ResponseWriter, r *http.Request, roomID string) {
+	query := r.URL.Query()
+	playerID := strings.TrimSpace(query.Get("playerId"))
+	if playerID == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_id", "playerId is required")
+		return
+	}
+	if !a.admitted(w, r, roomID) {
+		return
+	}
+
+	preview, err := a.store.PreviewPurchase(roomID, playerID, query.Get("cardId"), query.Get("source"))
 	if err != nil {
 		writeDomainError(w, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, preview)
+}
 
-	a.broadcastRoomSnapshotRefs(room, "action_applied")
-	writeJSON(w, http.StatusOK, room)
+func (a *App) handleGameLog(w http.ResponseWriter, r *http.Request, roomID string) {
+	if !a.admitted(w, r, roomID) {
+		return
+	}
+	query := r.URL.Query()
+	after, limit := 0, 0
+	var err error
+	if raw := query.Get("after"); raw != "" {
+		if after, err = strconv.Atoi(raw); err != nil || after < 0 {
+			writeError(w, http.StatusBadRequest, "invalid_pagination", "after must be a non-negative integer")
+			return
+		}
+	}
+	if raw := query.Get("limit"); raw != "" {
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			writeError(w, http.StatusBadRequest, "invalid_pagination", "limit must be a positive integer")
+			return
+		}
+	}
+
+	page, err := a.store.GameLog(roomID, after, limit)
+	if err != nil {
+		writeLobbyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
 }
 
 type wsClientMessage struct {
 	Type   string      `json:"type"`
 	Action game.Action `json:"action"`
+	CardID string      `json:"cardId,omitempty"`
+	Source string      `json:"source,omitempty"`
 }
 
 func (a *App) handleWS(w http.ResponseWriter, r *http.Request) {
 	roomID := strings.TrimSpace(r.URL.Query().Get("roomId"))
 	playerID := strings.TrimSpace(r.URL.Query().Get("playerId"))
+	wantsLegalActions, _ := strconv.ParseBool(r.URL.Query().Get("legalActions"))
 	if roomID == "" || playerID == "" {
 		writeError(w, http.StatusBadRequest, "invalid_query", "roomId and playerId are required")
 		return
@@ -244,14 +615,20 @@ func (a *App) handleWS(w http.ResponseWriter, r *http.Request) {
 		writeLobbyError(w, err)
 		return
 	}
+	if err := a.store.CheckAccess(roomID, playerID, r.URL.Query().Get("password")); err != nil {
+		writeLobbyError(w, err)
+		return
+	}
 
-	conn, err := a.upgrader.Upgrade(w, r, nil)
+	raw, err := a.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
+	conn := ws.NewConn(raw)
 	defer conn.Close()
 
-	a.hub.Add(roomID, conn)
+	client := ws.Client{PlayerID: playerID, LegalActions: wantsLegalActions}
+	a.hub.Add(roomID, conn, client)
 	defer a.hub.Remove(roomID, conn)
 
 	_ = a.store.SetConnected(roomID, playerID, true)
@@ -263,11 +640,7 @@ func (a *App) handleWS(w http.ResponseWriter, r *http.Request) {
 	}()
 
 	if room.Game != nil {
-		_ = conn.WriteJSON(map[string]any{
-			"type": "room_snapshot",
-			"reason": "connected",
-			"room": room,
-		})
+		_ = conn.WriteJSON(a.roomSnapshotMessage(room, "connected", client, nil))
 	}
 
 	if latestRoom, e := a.store.GetRoom(roomID); e == nil {
@@ -282,15 +655,29 @@ func (a *App) handleWS(w http.ResponseWriter, r *http.Request) {
 
 		switch strings.ToLower(strings.TrimSpace(msg.Type)) {
 		case "action":
-			updatedRoom, err := a.store.ApplyAction(roomID, playerID, msg.Action)
+			updatedRoom, events, err := a.store.ApplyAction(roomID, playerID, msg.Action)
 			if err != nil {
+				a.broadcastIfQuarantined(roomID, err)
 				_ = conn.WriteJSON(map[string]any{
 					"type": "action_error",
 					"error": err.Error(),
 				})
 				continue
 			}
-			a.broadcastRoomSnapshotRefs(updatedRoom, "action_applied")
+			a.broadcastRoomEvents(updatedRoom, "action_applied", events)
+		case "preview":
+			preview, err := a.store.PreviewPurchase(roomID, playerID, msg.CardID, msg.Source)
+			if err != nil {
+				_ = conn.WriteJSON(map[string]any{
+					"type":  "preview_error",
+					"error": err.Error(),
+				})
+				continue
+			}
+			_ = conn.WriteJSON(map[string]any{
+				"type":    "preview",
+				"preview": preview,
+			})
 		case "ping":
 			_ = conn.WriteJSON(map[string]any{"type": "pong"})
 		default:
@@ -302,18 +689,69 @@ func (a *App) handleWS(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (a *App) broadcastRoomSnapshot(roomID string, room *lobby.Room, reason string) {
-	a.hub.Broadcast(roomID, map[string]any{
+// disconnectPlayer closes the sockets a player who lost their seat still has
+// open on the room, by ID or code, after telling them why. Access is only
+// checked when a socket opens, so they would otherwise keep receiving
+// snapshots of a room they may no longer enter.
+func (a *App) disconnectPlayer(room *lobby.Room, playerID, reason string) {
+	msg := map[string]any{"type": reason, "roomId": room.ID}
+	a.hub.Disconnect(room.ID, playerID, msg)
+	if room.Code != "" && room.Code != room.ID {
+		a.hub.Disconnect(room.Code, playerID, msg)
+	}
+}
+
+func (a *App) broadcastRoomSnapshot(roomID string, room *lobby.Room, reason string, events []game.Event) {
+	a.hub.BroadcastEach(roomID, func(client ws.Client) any {
+		return a.roomSnapshotMessage(room, reason, client, events)
+	})
+}
+
+// roomSnapshotMessage builds the room_snapshot payload for one connection:
+// hidden cards are redacted for the viewer, the events that led to the
+// snapshot are attached when there are any, and so are legal actions when
+// the client opted in and is on turn.
+func (a *App) roomSnapshotMessage(room *lobby.Room, reason string, client ws.Client, events []game.Event) map[string]any {
+	msg := map[string]any{
 		"type":   "room_snapshot",
 		"reason": reason,
-		"room":   room,
-	})
+		"room":   room.RedactedFor(client.PlayerID),
+	}
+	if len(events) > 0 {
+		msg["events"] = game.RedactEvents(events, client.PlayerID)
+	}
+	if client.LegalActions && room.Game != nil && room.Game.CurrentPlayerID == client.PlayerID {
+		if actions, err := a.store.LegalActions(room.ID, client.PlayerID); err == nil {
+			msg["legalActions"] = actions
+		}
+	}
+	return msg
 }
 
 func (a *App) broadcastRoomSnapshotRefs(room *lobby.Room, reason string) {
-	a.broadcastRoomSnapshot(room.ID, room, reason)
+	a.broadcastRoomEvents(room, reason, nil)
+}
+
+// broadcastRoomEvents sends the snapshot together with the events that
+// produced it to everyone connected by room ID or code. Every room change
+// passes through here, so it also wakes a searching bot left on turn.
+func (a *App) broadcastRoomEvents(room *lobby.Room, reason string, events []game.Event) {
+	a.broadcastRoomSnapshot(room.ID, room, reason, events)
 	if room.Code != "" && room.Code != room.ID {
-		a.broadcastRoomSnapshot(room.Code, room, reason)
+		a.broadcastRoomSnapshot(room.Code, room, reason, events)
+	}
+	a.publishLobby(room)
+	a.playSearchingBots(room.ID)
+}
+
+// broadcastIfQuarantined tells the room when an action tripped the invariant
+// check and the room was taken out of play.
+func (a *App) broadcastIfQuarantined(roomID string, err error) {
+	if !errors.Is(err, game.ErrInvariantViolation) {
+		return
+	}
+	if room, e := a.store.GetRoom(roomID); e == nil {
+		a.broadcastRoomSnapshotRefs(room, "room_quarantined")
 	}
 }
 
@@ -325,7 +763,7 @@ func (a *App) startTimeoutLoop() {
 		for now := range ticker.C {
 			updates := a.store.ProcessTimeouts(now)
 			for _, update := range updates {
-				a.broadcastRoomSnapshotRefs(update.Room, "turn_timeout")
+				a.broadcastRoomEvents(update.Room, update.Reason, update.Events)
 			}
 		}
 	}()
@@ -352,8 +790,32 @@ func writeLobbyError(w http.ResponseWriter, err error) {
 		writeError(w, http.StatusNotFound, "player_not_found", err.Error())
 	case errors.Is(err, lobby.ErrInvalidTurnSeconds):
 		writeError(w, http.StatusBadRequest, "invalid_turn_seconds", err.Error())
+	case errors.Is(err, game.ErrInvalidRules):
+		writeError(w, http.StatusBadRequest, "invalid_rules", err.Error())
+	case errors.Is(err, game.ErrInvalidMode):
+		writeError(w, http.StatusBadRequest, "invalid_mode", err.Error())
+	case errors.Is(err, lobby.ErrInvalidTimeout):
+		writeError(w, http.StatusBadRequest, "invalid_timeout_policy", err.Error())
+	case errors.Is(err, lobby.ErrInvalidLogLimit):
+		writeError(w, http.StatusBadRequest, "invalid_log_limit", err.Error())
+	case errors.Is(err, lobby.ErrInvalidMaxPlayers):
+		writeError(w, http.StatusBadRequest, "invalid_max_players", err.Error())
+	case errors.Is(err, lobby.ErrInvalidVisibility):
+		writeError(w, http.StatusBadRequest, "invalid_visibility", err.Error())
+	case errors.Is(err, lobby.ErrInvalidPassword):
+		writeError(w, http.StatusForbidden, "invalid_password", err.Error())
+	case errors.Is(err, lobby.ErrPlayersNotReady):
+		writeError(w, http.StatusConflict, "players_not_ready", err.Error())
+	case errors.Is(err, lobby.ErrInvalidTarget):
+		writeError(w, http.StatusBadRequest, "invalid_target", err.Error())
+	case errors.Is(err, lobby.ErrInvalidSeatOrder):
+		writeError(w, http.StatusBadRequest, "invalid_seat_order", err.Error())
+	case errors.Is(err, bot.ErrUnknownDifficulty):
+		writeError(w, http.StatusBadRequest, "invalid_difficulty", err.Error())
 	case errors.Is(err, lobby.ErrOnlyHostCanStart):
 		writeError(w, http.StatusForbidden, "only_host_can_start", err.Error())
+	case errors.Is(err, lobby.ErrOnlyHost):
+		writeError(w, http.StatusForbidden, "only_host", err.Error())
 	case errors.Is(err, lobby.ErrInvalidStartState), errors.Is(err, lobby.ErrGameAlreadyStarted), errors.Is(err, lobby.ErrGameNotStarted):
 		writeError(w, http.StatusConflict, "invalid_room_state", err.Error())
 	default:
@@ -367,6 +829,9 @@ func writeDomainError(w http.ResponseWriter, err error) {
 		errors.Is(err, lobby.ErrPlayerNotFound),
 		errors.Is(err, lobby.ErrGameNotStarted):
 		writeLobbyError(w, err)
+	case errors.Is(err, lobby.ErrRoomQuarantined),
+		errors.Is(err, game.ErrInvariantViolation):
+		writeError(w, http.StatusConflict, "room_quarantined", err.Error())
 	case errors.Is(err, game.ErrNotPlayerTurn),
 		errors.Is(err, game.ErrUnknownAction),
 		errors.Is(err, game.ErrInvalidAction),
@@ -391,7 +856,7 @@ func withCORS(next http.Handler) http.Handler {
 		}
 		w.Header().Set("Access-Control-Allow-Origin", origin)
 		w.Header().Set("Vary", "Origin")
-		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PATCH,DELETE,OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type,Authorization")
 
 		if r.Method == http.MethodOptions {
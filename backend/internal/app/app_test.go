@@ -37,8 +37,8 @@ type roomDTO struct {
 }
 
 type createRoomResp struct {
-	Room   roomDTO     `json:"room"`
-	Player roomPlayer  `json:"player"`
+	Room   roomDTO    `json:"room"`
+	Player roomPlayer `json:"player"`
 }
 
 type joinRoomResp struct {
@@ -52,6 +52,28 @@ type wsMessage struct {
 	Room   *roomDTO               `json:"room,omitempty"`
 	Error  string                 `json:"error,omitempty"`
 	Extra  map[string]interface{} `json:"-"`
+
+	LegalActions []actionDTO `json:"legalActions,omitempty"`
+	Events       []eventDTO  `json:"events,omitempty"`
+}
+
+type eventDTO struct {
+	Type     string `json:"type"`
+	PlayerID string `json:"playerId"`
+	Card     *struct {
+		ID   string `json:"id"`
+		Tier int    `json:"tier"`
+	} `json:"card"`
+}
+
+type actionDTO struct {
+	Type    string         `json:"type"`
+	Payload map[string]any `json:"payload"`
+}
+
+type legalActionsResp struct {
+	PlayerID string      `json:"playerId"`
+	Actions  []actionDTO `json:"actions"`
 }
 
 func TestHTTPRoomGameFlow(t *testing.T) {
@@ -78,6 +100,7 @@ func TestHTTPRoomGameFlow(t *testing.T) {
 		t.Fatalf("expected 2 players after join, got %d", len(joinData.Room.Players))
 	}
 
+	readyAll(t, ts.URL, createData.Room.ID)
 	start := postJSON(t, ts.URL+"/api/rooms/"+createData.Room.ID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
 	var started roomDTO
 	decodeJSON(t, start, &started)
@@ -123,11 +146,12 @@ func TestHTTPActionNotPlayerTurn(t *testing.T) {
 	var joinData joinRoomResp
 	decodeJSON(t, join, &joinData)
 
+	readyAll(t, ts.URL, createData.Room.ID)
 	_ = postJSON(t, ts.URL+"/api/rooms/"+createData.Room.ID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
 
 	resp := postJSON(t, ts.URL+"/api/rooms/"+createData.Room.ID+"/actions", map[string]any{
 		"playerId": joinData.Player.ID,
-		"action": map[string]any{"type": "pass"},
+		"action":   map[string]any{"type": "pass"},
 	}, http.StatusBadRequest)
 	var errBody apiErr
 	decodeJSON(t, resp, &errBody)
@@ -146,6 +170,7 @@ func TestWebSocketPingAndInvalidAction(t *testing.T) {
 	decodeJSON(t, create, &createData)
 
 	_ = postJSON(t, ts.URL+"/api/rooms/"+createData.Room.ID+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	readyAll(t, ts.URL, createData.Room.ID)
 	_ = postJSON(t, ts.URL+"/api/rooms/"+createData.Room.ID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
 
 	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + createData.Player.ID
@@ -167,7 +192,7 @@ func TestWebSocketPingAndInvalidAction(t *testing.T) {
 	}
 
 	if err := conn.WriteJSON(map[string]any{
-		"type": "action",
+		"type":   "action",
 		"action": map[string]any{"type": "not_supported"},
 	}); err != nil {
 		t.Fatalf("write invalid action failed: %v", err)
@@ -182,6 +207,834 @@ func TestWebSocketPingAndInvalidAction(t *testing.T) {
 	}
 }
 
+func TestHTTPLegalActionsAndWebSocketOptIn(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+
+	join := postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	var joinData joinRoomResp
+	decodeJSON(t, join, &joinData)
+	readyAll(t, ts.URL, createData.Room.ID)
+	_ = postJSON(t, roomURL+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+
+	var hostActions legalActionsResp
+	decodeJSON(t, getURL(t, roomURL+"/legal-actions?playerId="+createData.Player.ID, http.StatusOK), &hostActions)
+	if len(hostActions.Actions) == 0 {
+		t.Fatal("expected legal actions for current player")
+	}
+	if last := hostActions.Actions[len(hostActions.Actions)-1]; last.Type != "pass" {
+		t.Fatalf("expected pass to be listed last, got %s", last.Type)
+	}
+
+	var guestActions legalActionsResp
+	decodeJSON(t, getURL(t, roomURL+"/legal-actions?playerId="+joinData.Player.ID, http.StatusOK), &guestActions)
+	if len(guestActions.Actions) != 0 {
+		t.Fatalf("expected no legal actions off turn, got %d", len(guestActions.Actions))
+	}
+
+	_ = getURL(t, roomURL+"/legal-actions", http.StatusBadRequest).Body.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + createData.Player.ID + "&legalActions=true"
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	msg, err := readUntilType(t, conn, "room_snapshot")
+	if err != nil {
+		t.Fatalf("expected initial room snapshot: %v", err)
+	}
+	if len(msg.LegalActions) != len(hostActions.Actions) {
+		t.Fatalf("expected %d legal actions in snapshot, got %d", len(hostActions.Actions), len(msg.LegalActions))
+	}
+}
+
+func TestHTTPBlindReserveRedactedForOpponents(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+
+	join := postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	var joinData joinRoomResp
+	decodeJSON(t, join, &joinData)
+	readyAll(t, ts.URL, createData.Room.ID)
+	_ = postJSON(t, roomURL+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+
+	_ = postJSON(t, roomURL+"/actions", map[string]any{
+		"playerId": createData.Player.ID,
+		"action": map[string]any{
+			"type":    "reserve_card",
+			"payload": map[string]any{"source": "deck", "tier": 1},
+		},
+	}, http.StatusOK).Body.Close()
+
+	type reservedView struct {
+		Players []struct {
+			Reserved []struct {
+				ID    string `json:"id"`
+				Tier  int    `json:"tier"`
+				Blind bool   `json:"blind"`
+			} `json:"reserved"`
+		} `json:"players"`
+	}
+
+	var ownView reservedView
+	decodeJSON(t, getURL(t, roomURL+"/state?playerId="+createData.Player.ID, http.StatusOK), &ownView)
+	if got := ownView.Players[0].Reserved; len(got) != 1 || got[0].ID == "" || !got[0].Blind {
+		t.Fatalf("expected owner to see blind card, got %+v", got)
+	}
+
+	var opponentView reservedView
+	decodeJSON(t, getURL(t, roomURL+"/state?playerId="+joinData.Player.ID, http.StatusOK), &opponentView)
+	if got := opponentView.Players[0].Reserved; len(got) != 1 || got[0].ID != "" || got[0].Tier != 1 {
+		t.Fatalf("expected opponent to see only the tier, got %+v", got)
+	}
+}
+
+func TestWebSocketBroadcastsEvents(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+
+	join := postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	var joinData joinRoomResp
+	decodeJSON(t, join, &joinData)
+	readyAll(t, ts.URL, createData.Room.ID)
+	_ = postJSON(t, roomURL+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + joinData.Player.ID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := readUntilType(t, conn, "room_snapshot"); err != nil {
+		t.Fatalf("expected initial room snapshot: %v", err)
+	}
+
+	_ = postJSON(t, roomURL+"/actions", map[string]any{
+		"playerId": createData.Player.ID,
+		"action": map[string]any{
+			"type":    "reserve_card",
+			"payload": map[string]any{"source": "deck", "tier": 1},
+		},
+	}, http.StatusOK).Body.Close()
+
+	for {
+		msg, err := readUntilType(t, conn, "room_snapshot")
+		if err != nil {
+			t.Fatalf("expected action snapshot: %v", err)
+		}
+		if msg.Reason != "action_applied" {
+			continue
+		}
+		if len(msg.Events) != 1 || msg.Events[0].Type != "card_reserved" || msg.Events[0].PlayerID != createData.Player.ID {
+			t.Fatalf("expected a single card_reserved event, got %+v", msg.Events)
+		}
+		if card := msg.Events[0].Card; card == nil || card.ID != "" || card.Tier != 1 {
+			t.Fatalf("expected the blind card redacted for the opponent, got %+v", card)
+		}
+		return
+	}
+}
+
+func TestHTTPCreateRoomWithRules(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{
+		"hostName":   "Alice",
+		"rulePreset": "quick",
+		"rules":      map[string]any{"goldCount": 3},
+	}, http.StatusCreated)
+	var created struct {
+		Room struct {
+			Rules struct {
+				Preset      string `json:"preset"`
+				TargetScore int    `json:"targetScore"`
+				GoldCount   int    `json:"goldCount"`
+			} `json:"rules"`
+		} `json:"room"`
+	}
+	decodeJSON(t, create, &created)
+	if got := created.Room.Rules; got.Preset != "custom" || got.TargetScore != 10 || got.GoldCount != 3 {
+		t.Fatalf("unexpected room rules: %+v", got)
+	}
+
+	resp := postJSON(t, ts.URL+"/api/rooms", map[string]any{
+		"hostName": "Alice",
+		"rules":    map[string]any{"reserveLimit": 9},
+	}, http.StatusBadRequest)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_rules" {
+		t.Fatalf("expected invalid_rules, got %s", errBody.Code)
+	}
+}
+
+func TestHTTPAddBot(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomID := createData.Room.ID
+
+	resp := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/bots", map[string]any{
+		"playerId":   createData.Player.ID,
+		"difficulty": "impossible",
+	}, http.StatusBadRequest)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_difficulty" {
+		t.Fatalf("expected invalid_difficulty, got %s", errBody.Code)
+	}
+
+	added := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/bots", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+	var addData joinRoomResp
+	decodeJSON(t, added, &addData)
+	if len(addData.Room.Players) != 2 || addData.Player.Name != "Bot 1" {
+		t.Fatalf("unexpected room after adding bot: %+v", addData)
+	}
+
+	readyAll(t, ts.URL, createData.Room.ID)
+	postJSON(t, ts.URL+"/api/rooms/"+roomID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+	acted := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/actions", map[string]any{
+		"playerId": createData.Player.ID,
+		"action":   map[string]any{"type": "take_tokens", "payload": map[string]any{"colors": []string{"white", "blue", "green"}}},
+	}, http.StatusOK)
+	var room roomDTO
+	decodeJSON(t, acted, &room)
+	if room.Game == nil || room.Game.CurrentPlayerID != createData.Player.ID || room.Game.Turn != 3 {
+		t.Fatalf("expected the bot to have played its turn, got %+v", room.Game)
+	}
+}
+
+func TestHardBotMovesAfterTheResponse(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	var createData createRoomResp
+	decodeJSON(t, postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated), &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+	postJSON(t, roomURL+"/bots", map[string]any{"playerId": createData.Player.ID, "difficulty": "hard"}, http.StatusOK).Body.Close()
+	postJSON(t, roomURL+"/start", map[string]any{"playerId": createData.Player.ID, "seed": 1}, http.StatusOK).Body.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + createData.Player.ID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	acted := postJSON(t, roomURL+"/actions", map[string]any{
+		"playerId": createData.Player.ID,
+		"action":   map[string]any{"type": "take_tokens", "payload": map[string]any{"colors": []string{"white", "blue", "green"}}},
+	}, http.StatusOK)
+	var room roomDTO
+	decodeJSON(t, acted, &room)
+	if room.Game == nil || room.Game.CurrentPlayerID == createData.Player.ID {
+		t.Fatalf("expected the hard bot to still be thinking, got %+v", room.Game)
+	}
+
+	// The search takes a while, so read without readUntilType's short
+	// deadline.
+	_ = conn.SetReadDeadline(time.Now().Add(30 * time.Second))
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("expected the bot's move: %v", err)
+		}
+		if msg.Reason == "action_applied" && msg.Room.Game.CurrentPlayerID == createData.Player.ID {
+			if msg.Room.Game.Turn != 3 || len(msg.Events) == 0 {
+				t.Fatalf("expected the bot's move with its events, got turn %d and %+v", msg.Room.Game.Turn, msg.Events)
+			}
+			return
+		}
+	}
+}
+
+func TestHTTPLeaveResigns(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomID := createData.Room.ID
+	join := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	var joinData joinRoomResp
+	decodeJSON(t, join, &joinData)
+	readyAll(t, ts.URL, createData.Room.ID)
+	postJSON(t, ts.URL+"/api/rooms/"+roomID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+
+	left := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/leave", map[string]any{"playerId": joinData.Player.ID}, http.StatusOK)
+	var room roomDTO
+	decodeJSON(t, left, &room)
+	if room.Status != "finished" || room.Game == nil || room.Game.Status != "finished" {
+		t.Fatalf("expected the game to end when Bob resigns, got %+v", room)
+	}
+
+	resp := postJSON(t, ts.URL+"/api/rooms/"+roomID+"/leave", map[string]any{"playerId": createData.Player.ID}, http.StatusBadRequest)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_action" {
+		t.Fatalf("expected invalid_action, got %s", errBody.Code)
+	}
+}
+
+func TestHTTPHostControls(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+	hostID := createData.Player.ID
+
+	var bob, carol joinRoomResp
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK), &bob)
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Carol"}, http.StatusOK), &carol)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + bob.Player.ID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := readUntilType(t, conn, "room_snapshot"); err != nil {
+		t.Fatalf("expected initial room snapshot: %v", err)
+	}
+	expectReason := func(want string) {
+		t.Helper()
+		msg, err := readUntilType(t, conn, "room_snapshot")
+		if err != nil || msg.Reason != want {
+			t.Fatalf("expected %s broadcast, got %q (%v)", want, msg.Reason, err)
+		}
+	}
+
+	resp := postJSON(t, roomURL+"/kick", map[string]any{"playerId": bob.Player.ID, "targetId": carol.Player.ID}, http.StatusForbidden)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "only_host" {
+		t.Fatalf("expected only_host, got %s", errBody.Code)
+	}
+
+	var room roomDTO
+	decodeJSON(t, postJSON(t, roomURL+"/kick", map[string]any{"playerId": hostID, "targetId": carol.Player.ID}, http.StatusOK), &room)
+	if len(room.Players) != 2 {
+		t.Fatalf("expected Carol to be removed, got %+v", room.Players)
+	}
+	expectReason("player_kicked")
+
+	decodeJSON(t, postJSON(t, roomURL+"/host", map[string]any{"playerId": hostID, "targetId": bob.Player.ID}, http.StatusOK), &room)
+	if room.HostID != bob.Player.ID {
+		t.Fatalf("expected Bob to be host, got %s", room.HostID)
+	}
+	expectReason("host_transferred")
+
+	resp = postJSON(t, roomURL+"/seats", map[string]any{"playerId": bob.Player.ID, "order": []string{bob.Player.ID}}, http.StatusBadRequest)
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_seat_order" {
+		t.Fatalf("expected invalid_seat_order, got %s", errBody.Code)
+	}
+	decodeJSON(t, postJSON(t, roomURL+"/seats", map[string]any{"playerId": bob.Player.ID, "order": []string{bob.Player.ID, hostID}}, http.StatusOK), &room)
+	if room.Players[0].ID != bob.Player.ID {
+		t.Fatalf("expected Bob in the first seat, got %+v", room.Players)
+	}
+	expectReason("seats_reordered")
+}
+
+func TestHTTPReadyCheckAndWaitingLeave(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+	hostID := createData.Player.ID
+
+	var bob, carol joinRoomResp
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK), &bob)
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Carol"}, http.StatusOK), &carol)
+
+	resp := postJSON(t, roomURL+"/start", map[string]any{"playerId": hostID}, http.StatusConflict)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "players_not_ready" {
+		t.Fatalf("expected players_not_ready, got %s", errBody.Code)
+	}
+
+	var room roomDTO
+	decodeJSON(t, postJSON(t, roomURL+"/leave", map[string]any{"playerId": carol.Player.ID}, http.StatusOK), &room)
+	if len(room.Players) != 2 {
+		t.Fatalf("expected Carol's seat to be freed, got %+v", room.Players)
+	}
+	postJSON(t, roomURL+"/ready", map[string]any{"playerId": bob.Player.ID, "ready": true}, http.StatusOK).Body.Close()
+	decodeJSON(t, postJSON(t, roomURL+"/start", map[string]any{"playerId": hostID}, http.StatusOK), &room)
+	if room.Status != "playing" {
+		t.Fatalf("expected the game to start, got %s", room.Status)
+	}
+
+	solo := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Dave"}, http.StatusCreated)
+	var soloData createRoomResp
+	decodeJSON(t, solo, &soloData)
+	decodeJSON(t, postJSON(t, ts.URL+"/api/rooms/"+soloData.Room.ID+"/leave", map[string]any{"playerId": soloData.Player.ID}, http.StatusOK), &room)
+	if room.Status != "closed" {
+		t.Fatalf("expected the empty room to close, got %s", room.Status)
+	}
+	getURL(t, ts.URL+"/api/rooms/"+soloData.Room.ID, http.StatusNotFound).Body.Close()
+}
+
+func TestHTTPUpdateSettings(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+	var bob joinRoomResp
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK), &bob)
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + bob.Player.ID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	if _, err := readUntilType(t, conn, "room_snapshot"); err != nil {
+		t.Fatalf("expected initial room snapshot: %v", err)
+	}
+
+	resp := patchJSON(t, roomURL+"/settings", map[string]any{"playerId": createData.Player.ID, "turnSeconds": 3}, http.StatusBadRequest)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_turn_seconds" {
+		t.Fatalf("expected invalid_turn_seconds, got %s", errBody.Code)
+	}
+	resp = patchJSON(t, roomURL+"/settings", map[string]any{"playerId": bob.Player.ID, "maxPlayers": 2}, http.StatusForbidden)
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "only_host" {
+		t.Fatalf("expected only_host, got %s", errBody.Code)
+	}
+
+	var updated struct {
+		TurnSeconds int    `json:"turnSeconds"`
+		MaxPlayers  int    `json:"maxPlayers"`
+		Visibility  string `json:"visibility"`
+		Rules       struct {
+			Preset      string `json:"preset"`
+			TargetScore int    `json:"targetScore"`
+			GoldCount   int    `json:"goldCount"`
+		} `json:"rules"`
+		TimeoutPolicy struct {
+			Mode string `json:"mode"`
+		} `json:"timeoutPolicy"`
+	}
+	decodeJSON(t, patchJSON(t, roomURL+"/settings", map[string]any{
+		"playerId":      createData.Player.ID,
+		"turnSeconds":   60,
+		"rulePreset":    "long",
+		"maxPlayers":    2,
+		"visibility":    "private",
+		"timeoutPolicy": map[string]any{"mode": "bot"},
+	}, http.StatusOK), &updated)
+	if updated.TurnSeconds != 60 || updated.MaxPlayers != 2 || updated.Visibility != "private" ||
+		updated.Rules.Preset != "long" || updated.TimeoutPolicy.Mode != "bot" {
+		t.Fatalf("unexpected settings: %+v", updated)
+	}
+	msg, err := readUntilType(t, conn, "room_snapshot")
+	if err != nil || msg.Reason != "settings_changed" {
+		t.Fatalf("expected settings_changed broadcast, got %q (%v)", msg.Reason, err)
+	}
+
+	// Rules alone adjust the room's current rules rather than standard ones.
+	decodeJSON(t, patchJSON(t, roomURL+"/settings", map[string]any{
+		"playerId": createData.Player.ID,
+		"rules":    map[string]any{"goldCount": 3},
+	}, http.StatusOK), &updated)
+	if updated.Rules.Preset != "custom" || updated.Rules.TargetScore != 21 || updated.Rules.GoldCount != 3 {
+		t.Fatalf("expected long rules with 3 gold, got %+v", updated.Rules)
+	}
+}
+
+func TestPasswordProtectedRoomOverHTTPAndWebSocket(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice", "password": "s3cret"}, http.StatusCreated)
+	var created struct {
+		Room struct {
+			ID          string `json:"id"`
+			Code        string `json:"code"`
+			Visibility  string `json:"visibility"`
+			HasPassword bool   `json:"hasPassword"`
+		} `json:"room"`
+		Player roomPlayer `json:"player"`
+	}
+	decodeJSON(t, create, &created)
+	if created.Room.Visibility != "private" || !created.Room.HasPassword {
+		t.Fatalf("expected a private, protected room: %+v", created.Room)
+	}
+	roomURL := ts.URL + "/api/rooms/" + created.Room.ID
+
+	resp := postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob", "password": "guess"}, http.StatusForbidden)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_password" {
+		t.Fatalf("expected invalid_password, got %s", errBody.Code)
+	}
+	var bob joinRoomResp
+	decodeJSON(t, postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob", "password": "s3cret"}, http.StatusOK), &bob)
+
+	// Reads need a seat or the password, so the player IDs cannot be
+	// learned and replayed.
+	for _, path := range []string{"/api/rooms/" + created.Room.Code, "/api/rooms/" + created.Room.ID + "/log", "/api/rooms/" + created.Room.ID + "/legal-actions?playerId=stranger"} {
+		getURL(t, ts.URL+path, http.StatusForbidden).Body.Close()
+	}
+	getURL(t, roomURL+"?playerId="+bob.Player.ID, http.StatusOK).Body.Close()
+	getURL(t, roomURL+"?password=s3cret", http.StatusOK).Body.Close()
+
+	wsBase := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + created.Room.ID
+	_, wsResp, err := websocket.DefaultDialer.Dial(wsBase+"&playerId=stranger", nil)
+	if err == nil || wsResp == nil || wsResp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a stranger to be refused, got %v", err)
+	}
+	for _, query := range []string{"&playerId=" + bob.Player.ID, "&playerId=stranger&password=s3cret"} {
+		conn, _, err := websocket.DefaultDialer.Dial(wsBase+query, nil)
+		if err != nil {
+			t.Fatalf("websocket dial %s failed: %v", query, err)
+		}
+		conn.Close()
+	}
+
+	// A kicked player's open socket is told and closed rather than kept
+	// on the room's snapshots.
+	conn, _, err := websocket.DefaultDialer.Dial(wsBase+"&playerId="+bob.Player.ID, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	postJSON(t, roomURL+"/kick", map[string]any{"playerId": created.Player.ID, "targetId": bob.Player.ID}, http.StatusOK).Body.Close()
+	if _, err := readUntilType(t, conn, "kicked"); err != nil {
+		t.Fatalf("expected a kicked message: %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
+		if msg.Type == "room_snapshot" {
+			t.Fatalf("kicked player still receives snapshots: %s", msg.Reason)
+		}
+	}
+}
+
+func TestRoomBrowserOverHTTPAndWebSocket(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	type lobbyMessage struct {
+		Type  string `json:"type"`
+		Rooms []struct {
+			ID string `json:"id"`
+		} `json:"rooms"`
+		Room struct {
+			ID       string `json:"id"`
+			HostName string `json:"hostName"`
+			Players  int    `json:"players"`
+		} `json:"room"`
+		RoomID string `json:"roomId"`
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/lobby", nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	next := func(want string) lobbyMessage {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		var msg lobbyMessage
+		if err := conn.ReadJSON(&msg); err != nil || msg.Type != want {
+			t.Fatalf("expected %s, got %+v (%v)", want, msg, err)
+		}
+		return msg
+	}
+	if msg := next("room_list"); len(msg.Rooms) != 0 {
+		t.Fatalf("expected an empty room list, got %+v", msg.Rooms)
+	}
+
+	postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Hidden", "visibility": "private"}, http.StatusCreated).Body.Close()
+	var createData createRoomResp
+	decodeJSON(t, postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated), &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+	if msg := next("room_created"); msg.Room.ID != createData.Room.ID || msg.Room.HostName != "Alice" {
+		t.Fatalf("unexpected room_created: %+v", msg)
+	}
+
+	postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK).Body.Close()
+	if msg := next("room_updated"); msg.Room.Players != 2 {
+		t.Fatalf("expected two players, got %+v", msg.Room)
+	}
+
+	var list struct {
+		Rooms []struct {
+			ID         string `json:"id"`
+			HostName   string `json:"hostName"`
+			Players    int    `json:"players"`
+			RulePreset string `json:"rulePreset"`
+		} `json:"rooms"`
+	}
+	decodeJSON(t, getURL(t, ts.URL+"/api/rooms?status=waiting&hasSeat=true", http.StatusOK), &list)
+	if len(list.Rooms) != 1 || list.Rooms[0].ID != createData.Room.ID || list.Rooms[0].RulePreset != "standard" {
+		t.Fatalf("expected only Alice's public room, got %+v", list.Rooms)
+	}
+	getURL(t, ts.URL+"/api/rooms?status=lost", http.StatusBadRequest).Body.Close()
+
+	stale, err := a.store.GetRoom(createData.Room.ID)
+	if err != nil {
+		t.Fatalf("get room: %v", err)
+	}
+	patchJSON(t, roomURL+"/settings", map[string]any{"playerId": createData.Player.ID, "visibility": "private"}, http.StatusOK).Body.Close()
+	if msg := next("room_closed"); msg.RoomID != createData.Room.ID {
+		t.Fatalf("unexpected room_closed: %+v", msg)
+	}
+
+	// A snapshot from before the room went private must not list it again.
+	a.publishLobby(stale)
+	if err := conn.WriteJSON(map[string]any{"type": "ping"}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	next("pong")
+
+	// A closed room is forgotten, and a late snapshot does not revive it.
+	var carol createRoomResp
+	decodeJSON(t, postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Carol"}, http.StatusCreated), &carol)
+	next("room_created")
+	stale, err = a.store.GetRoom(carol.Room.ID)
+	if err != nil {
+		t.Fatalf("get room: %v", err)
+	}
+	postJSON(t, ts.URL+"/api/rooms/"+carol.Room.ID+"/leave", map[string]any{"playerId": carol.Player.ID}, http.StatusOK).Body.Close()
+	if msg := next("room_closed"); msg.RoomID != carol.Room.ID {
+		t.Fatalf("unexpected room_closed: %+v", msg)
+	}
+	a.publishLobby(stale)
+	a.listedMu.Lock()
+	_, kept := a.listed[carol.Room.ID]
+	a.listedMu.Unlock()
+	if kept {
+		t.Fatal("expected the closed room's listing to be dropped")
+	}
+	if err := conn.WriteJSON(map[string]any{"type": "ping"}); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	next("pong")
+}
+
+func TestHTTPGameLog(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomID := createData.Room.ID
+	postJSON(t, ts.URL+"/api/rooms/"+roomID+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK)
+	readyAll(t, ts.URL, createData.Room.ID)
+	postJSON(t, ts.URL+"/api/rooms/"+roomID+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK)
+	postJSON(t, ts.URL+"/api/rooms/"+roomID+"/actions", map[string]any{
+		"playerId": createData.Player.ID,
+		"action":   map[string]any{"type": "take_tokens", "payload": map[string]any{"colors": []string{"white", "blue", "green"}}},
+	}, http.StatusOK)
+
+	var page struct {
+		Lines []struct {
+			Seq    int            `json:"seq"`
+			Key    string         `json:"key"`
+			Params map[string]any `json:"params"`
+			Text   string         `json:"text"`
+		} `json:"lines"`
+		HasMore bool `json:"hasMore"`
+	}
+	decodeJSON(t, getURL(t, ts.URL+"/api/rooms/"+roomID+"/log?after=1&limit=5", http.StatusOK), &page)
+	if len(page.Lines) != 1 || page.HasMore {
+		t.Fatalf("expected one line after seq 1, got %+v", page)
+	}
+	line := page.Lines[0]
+	if line.Seq != 2 || line.Key != "took_tokens" || line.Params["player"] != "Alice" || line.Text != "Alice took white, blue, green" {
+		t.Fatalf("unexpected log line: %+v", line)
+	}
+
+	var errBody apiErr
+	decodeJSON(t, getURL(t, ts.URL+"/api/rooms/"+roomID+"/log?limit=abc", http.StatusBadRequest), &errBody)
+	if errBody.Code != "invalid_pagination" {
+		t.Fatalf("expected invalid_pagination, got %s", errBody.Code)
+	}
+}
+
+func TestQuickMatchOverHTTPAndWebSocket(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	type ticketResp struct {
+		Ticket struct {
+			ID          string `json:"id"`
+			Status      string `json:"status"`
+			TurnSeconds int    `json:"turnSeconds"`
+			RoomID      string `json:"roomId"`
+			PlayerID    string `json:"playerId"`
+		} `json:"ticket"`
+	}
+	enqueue := func(name string) ticketResp {
+		t.Helper()
+		var out ticketResp
+		decodeJSON(t, postJSON(t, ts.URL+"/api/matchmaking/queue", map[string]any{"playerName": name, "players": 2, "turnSeconds": 45}, http.StatusCreated), &out)
+		if out.Ticket.ID == "" || out.Ticket.Status != "queued" || out.Ticket.TurnSeconds != 45 {
+			t.Fatalf("unexpected ticket: %+v", out.Ticket)
+		}
+		return out
+	}
+
+	var errBody apiErr
+	decodeJSON(t, postJSON(t, ts.URL+"/api/matchmaking/queue", map[string]any{"playerName": "Alice", "players": 5}, http.StatusBadRequest), &errBody)
+	if errBody.Code != "invalid_players" {
+		t.Fatalf("expected invalid_players, got %s", errBody.Code)
+	}
+
+	quitter := enqueue("Quitter")
+	var cancelled ticketResp
+	decodeJSON(t, deleteURL(t, ts.URL+"/api/matchmaking/queue/"+quitter.Ticket.ID, http.StatusOK), &cancelled)
+	if cancelled.Ticket.Status != "cancelled" {
+		t.Fatalf("expected cancelled ticket, got %+v", cancelled.Ticket)
+	}
+	deleteURL(t, ts.URL+"/api/matchmaking/queue/"+quitter.Ticket.ID, http.StatusConflict).Body.Close()
+	getURL(t, ts.URL+"/api/matchmaking/queue/missing", http.StatusNotFound).Body.Close()
+
+	alice := enqueue("Alice")
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws/matchmaking?ticketId="+alice.Ticket.ID, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+	next := func() ticketResp {
+		t.Helper()
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		var msg ticketResp
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read ticket failed: %v", err)
+		}
+		return msg
+	}
+	if msg := next(); msg.Ticket.Status != "queued" {
+		t.Fatalf("expected queued ticket, got %+v", msg.Ticket)
+	}
+
+	bob := enqueue("Bob")
+	var polled ticketResp
+	decodeJSON(t, getURL(t, ts.URL+"/api/matchmaking/queue/"+bob.Ticket.ID+"?wait=5", http.StatusOK), &polled)
+	if polled.Ticket.Status != "matched" || polled.Ticket.RoomID == "" || polled.Ticket.PlayerID == "" {
+		t.Fatalf("expected bob to be matched, got %+v", polled.Ticket)
+	}
+	pushed := next()
+	if pushed.Ticket.Status != "matched" || pushed.Ticket.RoomID != polled.Ticket.RoomID {
+		t.Fatalf("expected alice to be matched into bob's room, got %+v", pushed.Ticket)
+	}
+
+	var room roomDTO
+	decodeJSON(t, getURL(t, ts.URL+"/api/rooms/"+pushed.Ticket.RoomID, http.StatusOK), &room)
+	if room.Status != "playing" || room.HostID != pushed.Ticket.PlayerID || len(room.Players) != 2 {
+		t.Fatalf("expected a started two-player room hosted by alice, got %+v", room)
+	}
+}
+
+func TestPreviewOverHTTPAndWebSocket(t *testing.T) {
+	a := New()
+	ts := httptest.NewServer(a.Routes())
+	defer ts.Close()
+
+	create := postJSON(t, ts.URL+"/api/rooms", map[string]any{"hostName": "Alice"}, http.StatusCreated)
+	var createData createRoomResp
+	decodeJSON(t, create, &createData)
+	roomURL := ts.URL + "/api/rooms/" + createData.Room.ID
+
+	_ = postJSON(t, roomURL+"/join", map[string]any{"playerName": "Bob"}, http.StatusOK).Body.Close()
+	readyAll(t, ts.URL, createData.Room.ID)
+	_ = postJSON(t, roomURL+"/start", map[string]any{"playerId": createData.Player.ID}, http.StatusOK).Body.Close()
+
+	var state struct {
+		Tier1 []struct {
+			ID string `json:"id"`
+		} `json:"tier1"`
+	}
+	decodeJSON(t, getURL(t, roomURL+"/state", http.StatusOK), &state)
+	cardID := state.Tier1[0].ID
+
+	var preview struct {
+		CardID     string         `json:"cardId"`
+		Affordable bool           `json:"affordable"`
+		Shortfall  map[string]int `json:"shortfall"`
+	}
+	decodeJSON(t, getURL(t, roomURL+"/preview?playerId="+createData.Player.ID+"&cardId="+cardID, http.StatusOK), &preview)
+	if preview.CardID != cardID || preview.Affordable {
+		t.Fatalf("expected unaffordable preview for %s with no tokens, got %+v", cardID, preview)
+	}
+
+	resp := getURL(t, roomURL+"/preview?playerId="+createData.Player.ID+"&cardId=missing", http.StatusBadRequest)
+	var errBody apiErr
+	decodeJSON(t, resp, &errBody)
+	if errBody.Code != "invalid_action" {
+		t.Fatalf("expected invalid_action, got %s", errBody.Code)
+	}
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws?roomId=" + createData.Room.ID + "&playerId=" + createData.Player.ID
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.WriteJSON(map[string]any{"type": "preview", "cardId": cardID, "source": "tableau"}); err != nil {
+		t.Fatalf("write preview failed: %v", err)
+	}
+	if _, err := readUntilType(t, conn, "preview"); err != nil {
+		t.Fatalf("expected preview message: %v", err)
+	}
+}
+
 func TestCORSPreflight(t *testing.T) {
 	a := New()
 	ts := httptest.NewServer(a.Routes())
@@ -206,6 +1059,9 @@ func TestCORSPreflight(t *testing.T) {
 	if got := resp.Header.Get("Access-Control-Allow-Origin"); got != "http://localhost:5173" {
 		t.Fatalf("unexpected allow origin: %s", got)
 	}
+	if got := resp.Header.Get("Access-Control-Allow-Methods"); !strings.Contains(got, "PATCH") || !strings.Contains(got, "DELETE") {
+		t.Fatalf("expected PATCH and DELETE to be allowed, got %s", got)
+	}
 }
 
 func readUntilType(t *testing.T, conn *websocket.Conn, want string) (wsMessage, error) {
@@ -263,6 +1119,65 @@ func postJSON(t *testing.T, url string, payload any, wantStatus int) *http.Respo
 	return resp
 }
 
+func patchJSON(t *testing.T, url string, payload any, wantStatus int) *http.Response {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload failed: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("patch failed: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		defer resp.Body.Close()
+		var data map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&data)
+		t.Fatalf("unexpected status: got %d want %d body=%v", resp.StatusCode, wantStatus, data)
+	}
+	return resp
+}
+
+func getURL(t *testing.T, url string, wantStatus int) *http.Response {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		defer resp.Body.Close()
+		var data map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&data)
+		t.Fatalf("unexpected status: got %d want %d body=%v", resp.StatusCode, wantStatus, data)
+	}
+	return resp
+}
+
+func deleteURL(t *testing.T, url string, wantStatus int) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		t.Fatalf("create request failed: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("delete failed: %v", err)
+	}
+	if resp.StatusCode != wantStatus {
+		defer resp.Body.Close()
+		var data map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&data)
+		t.Fatalf("unexpected status: got %d want %d body=%v", resp.StatusCode, wantStatus, data)
+	}
+	return resp
+}
+
 func decodeJSON(t *testing.T, resp *http.Response, out any) {
 	t.Helper()
 	defer resp.Body.Close()
@@ -270,3 +1185,15 @@ func decodeJSON(t *testing.T, resp *http.Response, out any) {
 		t.Fatalf("decode json failed: %v", err)
 	}
 }
+
+// readyAll marks every player but the host ready over HTTP.
+func readyAll(t *testing.T, baseURL, roomID string) {
+	t.Helper()
+	var room roomDTO
+	decodeJSON(t, getURL(t, baseURL+"/api/rooms/"+roomID, http.StatusOK), &room)
+	for _, p := range room.Players {
+		if p.ID != room.HostID {
+			postJSON(t, baseURL+"/api/rooms/"+roomID+"/ready", map[string]any{"playerId": p.ID, "ready": true}, http.StatusOK).Body.Close()
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"errors"
+
+	"splendor/backend/internal/lobby"
+)
+
+// playSearchingBots makes sure a searching bot on turn in the room gets to
+// move. The search runs on its own goroutine without the store lock, at
+// most one per room; the goroutine keeps going while bots remain on turn.
+func (a *App) playSearchingBots(roomID string) {
+	a.botsMu.Lock()
+	defer a.botsMu.Unlock()
+	if a.thinking[roomID] {
+		return
+	}
+	a.thinking[roomID] = true
+	go a.runSearchingBots(roomID)
+}
+
+func (a *App) runSearchingBots(roomID string) {
+	for {
+		// Checking under botsMu means a turn handed to a bot after the
+		// check finds the flag cleared and starts a new goroutine.
+		a.botsMu.Lock()
+		turn, ok := a.store.BotTurn(roomID)
+		if !ok {
+			delete(a.thinking, roomID)
+			a.botsMu.Unlock()
+			return
+		}
+		a.botsMu.Unlock()
+
+		room, events, err := a.store.ApplyBotMove(turn, turn.Choose())
+		if errors.Is(err, lobby.ErrStaleBotTurn) {
+			continue
+		}
+		if err != nil {
+			// Anything else left the room as it was; trying again would
+			// only spin.
+			a.broadcastIfQuarantined(roomID, err)
+			a.botsMu.Lock()
+			delete(a.thinking, roomID)
+			a.botsMu.Unlock()
+			return
+		}
+		a.broadcastRoomEvents(room, "action_applied", events)
+	}
+}
@@ -0,0 +1,139 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"splendor/backend/internal/lobby"
+	"splendor/backend/internal/ws"
+)
+
+// lobbyChannel is the hub key of lobby-page connections. Room IDs and codes
+// never contain '*'.
+const lobbyChannel = "*lobby"
+
+func (a *App) handleListRooms(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseRoomFilter(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"rooms": a.store.ListRooms(filter, time.Now().UTC())})
+}
+
+func parseRoomFilter(w http.ResponseWriter, r *http.Request) (lobby.RoomFilter, bool) {
+	query := r.URL.Query()
+	var filter lobby.RoomFilter
+	switch status := lobby.RoomStatus(strings.ToLower(strings.TrimSpace(query.Get("status")))); status {
+	case "", lobby.RoomWaiting, lobby.RoomPlaying, lobby.RoomFinished:
+		filter.Status = status
+	default:
+		writeError(w, http.StatusBadRequest, "invalid_query", "status must be waiting, playing or finished")
+		return filter, false
+	}
+	if raw := query.Get("hasSeat"); raw != "" {
+		hasSeat, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_query", "hasSeat must be a boolean")
+			return filter, false
+		}
+		filter.HasSeat = hasSeat
+	}
+	return filter, true
+}
+
+// handleLobbyWS streams room browser changes. The first message lists the
+// rooms matching the query filter; after that room_created, room_updated and
+// room_closed cover every public room, for the client to filter.
+func (a *App) handleLobbyWS(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseRoomFilter(w, r)
+	if !ok {
+		return
+	}
+
+	raw, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := ws.NewConn(raw)
+	defer conn.Close()
+
+	a.hub.Add(lobbyChannel, conn, ws.Client{})
+	defer a.hub.Remove(lobbyChannel, conn)
+
+	_ = conn.WriteJSON(map[string]any{
+		"type":  "room_list",
+		"rooms": a.store.ListRooms(filter, time.Now().UTC()),
+	})
+
+	for {
+		var msg wsClientMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			break
+		}
+		if strings.EqualFold(strings.TrimSpace(msg.Type), "ping") {
+			_ = conn.WriteJSON(map[string]any{"type": "pong"})
+		}
+	}
+}
+
+// lobbyListing is what the lobby channel was last told about a room.
+type lobbyListing struct {
+	version uint64
+	listed  bool
+	summary lobby.RoomSummary
+}
+
+// publishLobby tells lobby connections how a room's listing changed. Only
+// changes to the listing itself are sent, so moves in a running game stay
+// off the channel. A room that turns private or closes is reported closed.
+//
+// Snapshots can reach here out of order, so one older than the last
+// published version of the room is dropped, and the message goes out under
+// listedMu so clients receive changes in the same order. A closed room has
+// left the store for good, so its entry is dropped once room_closed is sent;
+// a late snapshot of a room the store no longer has is ignored.
+func (a *App) publishLobby(room *lobby.Room) {
+	now := time.Now().UTC()
+	summary := room.Summary(now)
+	key := summary
+	key.AgeSeconds = 0
+
+	a.listedMu.Lock()
+	defer a.listedMu.Unlock()
+
+	previous, seen := a.listed[room.ID]
+	if seen && room.Version < previous.version {
+		return
+	}
+	if !seen && room.Status != lobby.RoomClosed {
+		if _, err := a.store.GetRoom(room.ID); err != nil {
+			return
+		}
+	}
+	var msg map[string]any
+	switch {
+	case !room.Public():
+		if previous.listed {
+			msg = map[string]any{"type": "room_closed", "roomId": room.ID}
+		}
+		if room.Status == lobby.RoomClosed {
+			delete(a.listed, room.ID)
+		} else {
+			a.listed[room.ID] = lobbyListing{version: room.Version}
+		}
+	case !previous.listed:
+		a.listed[room.ID] = lobbyListing{version: room.Version, listed: true, summary: key}
+		msg = map[string]any{"type": "room_created", "room": summary}
+	default:
+		a.listed[room.ID] = lobbyListing{version: room.Version, listed: true, summary: key}
+		if previous.summary != key {
+			msg = map[string]any{"type": "room_updated", "room": summary}
+		}
+	}
+
+	if msg != nil {
+		a.hub.Broadcast(lobbyChannel, msg)
+	}
+}
@@ -0,0 +1,179 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"splendor/backend/internal/matchmaking"
+	"splendor/backend/internal/ws"
+)
+
+// maxTicketWait caps how long a long-poll on a ticket may hang.
+const maxTicketWait = 60 * time.Second
+
+type enqueueRequest struct {
+	PlayerName  string `json:"playerName"`
+	Players     int    `json:"players"`
+	TurnSeconds int    `json:"turnSeconds,omitempty"`
+}
+
+func (a *App) handleEnqueue(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+
+	var req enqueueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json body")
+		return
+	}
+	if strings.TrimSpace(req.PlayerName) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_player_name", "playerName is required")
+		return
+	}
+
+	ticket, err := a.queue.Enqueue(matchmaking.Request{
+		PlayerName:  req.PlayerName,
+		Players:     req.Players,
+		TurnSeconds: req.TurnSeconds,
+	}, time.Now())
+	if err != nil {
+		writeMatchmakingError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"ticket": ticket})
+}
+
+// handleTicket reads a ticket (GET, optionally long-polling with ?wait=
+// seconds while it is still queued) or cancels it (DELETE).
+func (a *App) handleTicket(w http.ResponseWriter, r *http.Request) {
+	ticketID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/matchmaking/queue/"), "/")
+	if ticketID == "" || strings.Contains(ticketID, "/") {
+		writeError(w, http.StatusNotFound, "not_found", "resource not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		a.handleGetTicket(w, r, ticketID)
+	case http.MethodDelete:
+		ticket, err := a.queue.Cancel(ticketID, time.Now())
+		if err != nil {
+			writeMatchmakingError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"ticket": ticket})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+	}
+}
+
+func (a *App) handleGetTicket(w http.ResponseWriter, r *http.Request, ticketID string) {
+	wait := time.Duration(0)
+	if raw := r.URL.Query().Get("wait"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > maxTicketWait {
+			writeError(w, http.StatusBadRequest, "invalid_query", "wait must be between 0 and 60 seconds")
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	ticket, changed, err := a.queue.Ticket(ticketID)
+	if err != nil {
+		writeMatchmakingError(w, err)
+		return
+	}
+	if ticket.Status == matchmaking.TicketQueued && wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-changed:
+			ticket, _, err = a.queue.Ticket(ticketID)
+			if err != nil {
+				writeMatchmakingError(w, err)
+				return
+			}
+		case <-timer.C:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ticket": ticket})
+}
+
+// handleMatchmakingWS pushes a ticket's state as it changes. The connection
+// is closed once the ticket is matched, cancelled or expired.
+func (a *App) handleMatchmakingWS(w http.ResponseWriter, r *http.Request) {
+	ticketID := r.URL.Query().Get("ticketId")
+	ticket, changed, err := a.queue.Ticket(ticketID)
+	if err != nil {
+		writeMatchmakingError(w, err)
+		return
+	}
+
+	raw, err := a.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := ws.NewConn(raw)
+	defer conn.Close()
+
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		if err := conn.WriteJSON(map[string]any{"type": "ticket", "ticket": ticket}); err != nil {
+			return
+		}
+		if ticket.Status != matchmaking.TicketQueued {
+			return
+		}
+		select {
+		case <-changed:
+		case <-gone:
+			return
+		}
+		if ticket, changed, err = a.queue.Ticket(ticketID); err != nil {
+			return
+		}
+	}
+}
+
+func (a *App) startMatchLoop() {
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for now := range ticker.C {
+			for _, match := range a.queue.Match(now) {
+				a.broadcastRoomSnapshotRefs(match.Room, "game_started")
+			}
+		}
+	}()
+}
+
+func writeMatchmakingError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, matchmaking.ErrInvalidPlayers):
+		writeError(w, http.StatusBadRequest, "invalid_players", err.Error())
+	case errors.Is(err, matchmaking.ErrTicketNotFound):
+		writeError(w, http.StatusNotFound, "ticket_not_found", err.Error())
+	case errors.Is(err, matchmaking.ErrTicketClosed):
+		writeError(w, http.StatusConflict, "ticket_closed", err.Error())
+	default:
+		writeLobbyError(w, err)
+	}
+}
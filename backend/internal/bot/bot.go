@@ -0,0 +1,227 @@
+// Package bot plays Splendor seats on behalf of absent players.
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"splendor/backend/internal/game"
+)
+
+const (
+	DifficultyEasy   = "easy"
+	DifficultyNormal = "normal"
+	DifficultyHard   = "hard"
+)
+
+var ErrUnknownDifficulty = errors.New("unknown bot difficulty")
+
+// Policy picks the next move for playerID. The state is what that player is
+// allowed to see; the returned action should be one of
+// game.LegalActionsFor(state, playerID).
+type Policy interface {
+	Choose(state game.State, playerID string) game.Action
+}
+
+// NormalizeDifficulty maps an empty difficulty to normal and rejects
+// unknown ones.
+func NormalizeDifficulty(difficulty string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(difficulty)) {
+	case "", DifficultyNormal:
+		return DifficultyNormal, nil
+	case DifficultyEasy:
+		return DifficultyEasy, nil
+	case DifficultyHard:
+		return DifficultyHard, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownDifficulty, difficulty)
+	}
+}
+
+// New returns the policy for a difficulty: greedy for easy and normal,
+// MCTS for hard. The seed drives tie breaks, sampling and, for easy bots,
+// their deliberate mistakes.
+func New(difficulty string, seed int64) (Policy, error) {
+	normalized, err := NormalizeDifficulty(difficulty)
+	if err != nil {
+		return nil, err
+	}
+	if normalized == DifficultyHard {
+		return NewMCTS(DefaultMCTSConfig(), seed), nil
+	}
+	g := &Greedy{rng: rand.New(rand.NewSource(seed))}
+	if normalized == DifficultyEasy {
+		g.randomness = 0.35
+	}
+	return g, nil
+}
+
+// Greedy scores every legal action one move deep, favouring points, noble
+// progress and tokens that bring good cards within reach.
+type Greedy struct {
+	rng *rand.Rand
+	// randomness is the chance of playing a random non-pass move instead of
+	// the best one.
+	randomness float64
+}
+
+func (g *Greedy) Choose(state game.State, playerID string) game.Action {
+	actions := game.LegalActionsFor(state, playerID)
+	if len(actions) == 0 {
+		return game.Action{Type: "pass"}
+	}
+	g.rng.Shuffle(len(actions), func(i, j int) { actions[i], actions[j] = actions[j], actions[i] })
+
+	if g.randomness > 0 && g.rng.Float64() < g.randomness {
+		for _, action := range actions {
+			if action.Type != "pass" {
+				return action
+			}
+		}
+	}
+
+	return ranked(state, playerID, actions)[0]
+}
+
+// ranked orders actions from the best score down; ties keep their order.
+func ranked(state game.State, playerID string, actions []game.Action) []game.Action {
+	p, ok := findPlayer(state, playerID)
+	if !ok {
+		return actions
+	}
+	want := wanted(state, p)
+	scores := make([]float64, len(actions))
+	order := make([]int, len(actions))
+	for i, action := range actions {
+		scores[i] = score(state, p, want, action)
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	out := make([]game.Action, len(actions))
+	for i, idx := range order {
+		out[i] = actions[idx]
+	}
+	return out
+}
+
+func score(state game.State, p game.PlayerState, want map[string]float64, action game.Action) float64 {
+	switch action.Type {
+	case "buy_card":
+		card, ok := findCard(state, p, action.Payload.CardID)
+		if !ok {
+			return 0
+		}
+		return 10 + 3*float64(card.Points) + 2*nobleHelp(state, p, card.Bonus)
+	case "claim_noble":
+		for _, noble := range state.Nobles {
+			if noble.ID == action.Payload.NobleID {
+				return 20 + float64(noble.Points)
+			}
+		}
+		return 20
+	case "take_tokens":
+		total := 1.0
+		for _, color := range action.Payload.Colors {
+			total += want[color]
+		}
+		overflow := p.Tokens.Total() + len(action.Payload.Colors) - state.Rules.TokenLimit
+		if overflow > 0 {
+			total -= float64(overflow)
+		}
+		return total
+	case "reserve_card":
+		total := -0.5
+		if state.Bank.Gold > 0 {
+			total += 1
+		}
+		if card, ok := findCard(state, p, action.Payload.CardID); ok {
+			total += 0.4 * float64(card.Points)
+		}
+		return total
+	case "discard_tokens":
+		total := 0.0
+		for _, color := range action.Payload.Colors {
+			if color == game.GemGold {
+				total -= 5
+				continue
+			}
+			total -= want[color]
+		}
+		return total
+	default:
+		return -100
+	}
+}
+
+// wanted weighs each color by how much it would help towards the visible
+// cards the player could buy soon, with cheap and valuable cards counting
+// most.
+func wanted(state game.State, p game.PlayerState) map[string]float64 {
+	out := make(map[string]float64, len(game.ColoredGems))
+	for _, card := range candidateCards(state, p) {
+		missing := 0
+		for _, color := range game.ColoredGems {
+			missing += deficit(card, p, color)
+		}
+		if missing == 0 {
+			continue
+		}
+		weight := (1 + float64(card.Points) + nobleHelp(state, p, card.Bonus)) / float64(missing)
+		for _, color := range game.ColoredGems {
+			if deficit(card, p, color) > 0 {
+				out[color] += weight
+			}
+		}
+	}
+	return out
+}
+
+func deficit(card game.Card, p game.PlayerState, color string) int {
+	return max(0, card.Cost.Get(color)-p.Bonuses.Get(color)-p.Tokens.Get(color))
+}
+
+// nobleHelp is how much one more bonus of color moves the player towards
+// the nobles still on the table.
+func nobleHelp(state game.State, p game.PlayerState, color string) float64 {
+	total := 0.0
+	for _, noble := range state.Nobles {
+		if noble.Requirement.Get(color) <= p.Bonuses.Get(color) {
+			continue
+		}
+		remaining := 0
+		for _, c := range game.ColoredGems {
+			remaining += max(0, noble.Requirement.Get(c)-p.Bonuses.Get(c))
+		}
+		total += float64(noble.Points) / float64(remaining)
+	}
+	return total
+}
+
+func candidateCards(state game.State, p game.PlayerState) []game.Card {
+	cards := make([]game.Card, 0, 12+len(p.Reserved))
+	cards = append(cards, state.Tier1...)
+	cards = append(cards, state.Tier2...)
+	cards = append(cards, state.Tier3...)
+	return append(cards, p.Reserved...)
+}
+
+func findCard(state game.State, p game.PlayerState, cardID string) (game.Card, bool) {
+	for _, card := range candidateCards(state, p) {
+		if card.ID == cardID {
+			return card, true
+		}
+	}
+	return game.Card{}, false
+}
+
+func findPlayer(state game.State, playerID string) (game.PlayerState, bool) {
+	for _, p := range state.Players {
+		if p.ID == playerID {
+			return p, true
+		}
+	}
+	return game.PlayerState{}, false
+}
@@ -0,0 +1,172 @@
+package bot
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"splendor/backend/internal/game"
+)
+
+func TestGreedyBotsFinishGame(t *testing.T) {
+	for _, difficulty := range []string{DifficultyEasy, DifficultyNormal} {
+		seats := []game.Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}}
+		engine, err := game.NewWithOptions(seats, game.Options{Seed: 5, Mode: game.ModeStrict, CheckInvariants: true})
+		if err != nil {
+			t.Fatalf("new game failed: %v", err)
+		}
+		policies := make(map[string]Policy)
+		for i, seat := range seats {
+			policy, err := New(difficulty, int64(i))
+			if err != nil {
+				t.Fatalf("new bot failed: %v", err)
+			}
+			policies[seat.ID] = policy
+		}
+
+		for moves := 0; engine.Snapshot().Status != game.StatusFinished; moves++ {
+			if moves > 1000 {
+				t.Fatalf("%s bots did not finish the game", difficulty)
+			}
+			state := engine.Snapshot()
+			current := state.CurrentPlayerID
+			action := policies[current].Choose(state.RedactedFor(current), current)
+			if _, err := engine.Apply(current, action); err != nil {
+				t.Fatalf("%s bot chose rejected action %+v: %v", difficulty, action, err)
+			}
+		}
+		if len(engine.Snapshot().WinnerIDs) == 0 {
+			t.Fatalf("%s game finished without winners", difficulty)
+		}
+	}
+}
+
+func TestGreedyPrefersPoints(t *testing.T) {
+	engine, err := game.NewWithSeed([]game.Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 3)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	state := engine.Snapshot()
+	rich := game.TokenSet{White: 7, Blue: 7, Green: 7, Red: 7, Black: 7}
+	state.Players[0].Tokens = rich
+	points := make(map[string]int)
+	most := 0
+	for _, card := range append(append(state.Tier1, state.Tier2...), state.Tier3...) {
+		points[card.ID] = card.Points
+		most = max(most, card.Points)
+	}
+
+	policy, _ := New(DifficultyNormal, 1)
+	action := policy.Choose(state, "p1")
+	if action.Type != "buy_card" || points[action.Payload.CardID] != most {
+		t.Fatalf("expected to buy a %d point card, got %+v", most, action)
+	}
+}
+
+func TestNormalizeDifficulty(t *testing.T) {
+	if got, err := NormalizeDifficulty(""); err != nil || got != DifficultyNormal {
+		t.Fatalf("expected default normal, got %q %v", got, err)
+	}
+	if _, err := NormalizeDifficulty("grandmaster"); !errors.Is(err, ErrUnknownDifficulty) {
+		t.Fatalf("expected ErrUnknownDifficulty, got %v", err)
+	}
+}
+
+func TestMCTSPlaysLegalMovesToTheEnd(t *testing.T) {
+	seats := []game.Seat{{ID: "hard", Name: "A"}, {ID: "greedy", Name: "B"}}
+	engine, err := game.NewWithOptions(seats, game.Options{Seed: 9, Mode: game.ModeStrict})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	greedy, _ := New(DifficultyNormal, 1)
+	policies := map[string]Policy{
+		"hard":   NewMCTS(MCTSConfig{Iterations: 40}, 1),
+		"greedy": greedy,
+	}
+
+	for moves := 0; engine.Snapshot().Status != game.StatusFinished; moves++ {
+		if moves > 1000 {
+			t.Fatal("game did not finish")
+		}
+		current := engine.CurrentPlayerID()
+		action := policies[current].Choose(engine.Snapshot().RedactedFor(current), current)
+		if _, err := engine.Apply(current, action); err != nil {
+			t.Fatalf("%s chose rejected action %+v: %v", current, action, err)
+		}
+	}
+}
+
+func TestHardBeatsNormal(t *testing.T) {
+	if os.Getenv("BOT_MATCHES") == "" {
+		t.Skip("plays several full games at the hard budget; set BOT_MATCHES=1 to run")
+	}
+	// The search counts iterations only, so these games always play out the
+	// same way. Seats alternate so neither bot always moves first.
+	hardWins, normalWins := 0, 0
+	for seed := int64(1); seed <= 6; seed++ {
+		seats := []game.Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}
+		hardID := seats[seed%2].ID
+		engine, err := game.NewWithOptions(seats, game.Options{Seed: seed, Mode: game.ModeStrict})
+		if err != nil {
+			t.Fatalf("new game failed: %v", err)
+		}
+		hard, _ := New(DifficultyHard, seed)
+		normal, _ := New(DifficultyNormal, seed)
+
+		for moves := 0; engine.Snapshot().Status != game.StatusFinished; moves++ {
+			if moves > 1000 {
+				t.Fatalf("seed %d: game did not finish", seed)
+			}
+			current := engine.CurrentPlayerID()
+			policy := normal
+			if current == hardID {
+				policy = hard
+			}
+			action := policy.Choose(engine.Snapshot().RedactedFor(current), current)
+			if _, err := engine.Apply(current, action); err != nil {
+				t.Fatalf("seed %d: %s chose rejected action %+v: %v", seed, current, action, err)
+			}
+		}
+		for _, id := range engine.Snapshot().WinnerIDs {
+			if id == hardID {
+				hardWins++
+			} else {
+				normalWins++
+			}
+		}
+	}
+	if hardWins <= normalWins {
+		t.Fatalf("hard won %d games and normal %d", hardWins, normalWins)
+	}
+}
+
+func TestMCTSRespectsTimeLimit(t *testing.T) {
+	engine, err := game.NewWithSeed([]game.Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 3)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	state := engine.Snapshot().RedactedFor("p1")
+
+	start := time.Now()
+	action := NewMCTS(MCTSConfig{TimeLimit: 50 * time.Millisecond}, 2).Choose(state, "p1")
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("search ran for %s with a 50ms budget", elapsed)
+	}
+	if _, err := engine.Apply("p1", action); err != nil {
+		t.Fatalf("chosen action rejected: %v", err)
+	}
+}
+
+func BenchmarkMCTSChoose(b *testing.B) {
+	engine, err := game.NewWithSeed([]game.Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}}, 4)
+	if err != nil {
+		b.Fatal(err)
+	}
+	state := engine.Snapshot().RedactedFor("p1")
+	policy := NewMCTS(MCTSConfig{Iterations: 100}, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		policy.Choose(state, "p1")
+	}
+}
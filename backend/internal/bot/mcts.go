@@ -0,0 +1,240 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"splendor/backend/internal/game"
+)
+
+// MCTSConfig bounds a search. The search stops at whichever of Iterations
+// and TimeLimit is reached first; a zero field is no limit, and when both
+// are zero DefaultMCTSConfig's iteration count is used.
+type MCTSConfig struct {
+	Iterations int
+	TimeLimit  time.Duration
+	// Exploration is the UCB constant; zero means sqrt(2).
+	Exploration float64
+	// RolloutDepth is how many moves a playout makes before the position is
+	// scored; zero means 8.
+	RolloutDepth int
+	// Candidates is how many of the greedy bot's best scored moves are
+	// searched in each position; zero means 6.
+	Candidates int
+}
+
+// DefaultMCTSConfig is the budget of the hard difficulty. Rooms run the
+// search without holding their lock, so it can afford a few hundred
+// milliseconds a move. It counts iterations only, so a seeded bot plays the
+// same moves however fast the machine is.
+func DefaultMCTSConfig() MCTSConfig {
+	return MCTSConfig{Iterations: 400}
+}
+
+// MCTS is an information-set Monte Carlo tree search. A search builds one
+// engine consistent with what the player sees with game.Determinize; each
+// iteration clones it and redraws every card the player cannot see, the
+// decks and opponents' blind reservations alike, then walks a single tree
+// shared by all samples and finishes with a short greedy playout. The tree
+// only branches on the greedy bot's best scored moves.
+type MCTS struct {
+	config   MCTSConfig
+	rng      *rand.Rand
+	fallback *Greedy
+}
+
+func NewMCTS(config MCTSConfig, seed int64) *MCTS {
+	if config.Iterations <= 0 && config.TimeLimit <= 0 {
+		config.Iterations = DefaultMCTSConfig().Iterations
+	}
+	if config.Exploration <= 0 {
+		config.Exploration = math.Sqrt2
+	}
+	if config.RolloutDepth <= 0 {
+		config.RolloutDepth = 8
+	}
+	if config.Candidates <= 0 {
+		config.Candidates = 6
+	}
+	rng := rand.New(rand.NewSource(seed))
+	return &MCTS{config: config, rng: rng, fallback: &Greedy{rng: rng}}
+}
+
+type searchNode struct {
+	action game.Action
+	// mover played action to reach this node; rewards are from their side.
+	mover    string
+	children map[string]*searchNode
+	visits   int
+	// available counts the iterations in which the node's action was legal,
+	// which replaces the parent's visit count in ISMCTS.
+	available int
+	reward    float64
+}
+
+func (m *MCTS) Choose(state game.State, playerID string) game.Action {
+	actions := game.LegalActionsFor(state, playerID)
+	if len(actions) <= 1 {
+		return m.fallback.Choose(state, playerID)
+	}
+
+	sample, err := game.Determinize(state, playerID, m.rng)
+	if err != nil {
+		return m.fallback.Choose(state, playerID)
+	}
+	root := &searchNode{children: make(map[string]*searchNode)}
+	start := time.Now()
+	for i := 0; ; i++ {
+		if m.config.Iterations > 0 && i >= m.config.Iterations {
+			break
+		}
+		if m.config.TimeLimit > 0 && time.Since(start) >= m.config.TimeLimit {
+			break
+		}
+		engine := sample.Clone()
+		engine.Resample(playerID, m.rng)
+		m.iterate(root, engine)
+	}
+
+	var best *searchNode
+	for _, action := range actions {
+		child := root.children[actionKey(action)]
+		if child != nil && (best == nil || child.visits > best.visits) {
+			best = child
+		}
+	}
+	if best == nil {
+		return m.fallback.Choose(state, playerID)
+	}
+	return best.action
+}
+
+// iterate runs one selection, expansion, playout and backpropagation pass
+// on a determinized engine.
+func (m *MCTS) iterate(root *searchNode, engine *game.Engine) {
+	path := []*searchNode{root}
+	node := root
+	for {
+		mover := engine.CurrentPlayerID()
+		if mover == "" {
+			break
+		}
+		legal := m.candidates(engine, mover)
+		if len(legal) == 0 {
+			break
+		}
+
+		var untried []game.Action
+		var candidates []*searchNode
+		for _, action := range legal {
+			child, ok := node.children[actionKey(action)]
+			if !ok {
+				untried = append(untried, action)
+				continue
+			}
+			child.available++
+			candidates = append(candidates, child)
+		}
+
+		if len(untried) > 0 {
+			action := untried[0]
+			if _, err := engine.Apply(mover, action); err != nil {
+				break
+			}
+			child := &searchNode{action: action, mover: mover, children: make(map[string]*searchNode), available: 1}
+			node.children[actionKey(action)] = child
+			path = append(path, child)
+			break
+		}
+
+		node = m.selectChild(candidates)
+		if _, err := engine.Apply(mover, node.action); err != nil {
+			break
+		}
+		path = append(path, node)
+	}
+
+	m.playout(engine)
+	rewards := evaluate(engine.Snapshot())
+	for _, n := range path[1:] {
+		n.visits++
+		n.reward += rewards[n.mover]
+	}
+}
+
+func (m *MCTS) selectChild(candidates []*searchNode) *searchNode {
+	var best *searchNode
+	bestScore := math.Inf(-1)
+	for _, child := range candidates {
+		score := child.reward/float64(child.visits) +
+			m.config.Exploration*math.Sqrt(math.Log(float64(child.available))/float64(child.visits))
+		if score > bestScore {
+			best, bestScore = child, score
+		}
+	}
+	return best
+}
+
+// candidates are the mover's legal actions the search considers, best
+// scored first, so expansion tries the greedy bot's favourite move first.
+func (m *MCTS) candidates(engine *game.Engine, mover string) []game.Action {
+	legal := ranked(engine.Snapshot(), mover, engine.LegalActions(mover))
+	if len(legal) > m.config.Candidates {
+		legal = legal[:m.config.Candidates]
+	}
+	return legal
+}
+
+// playout continues the game with the greedy bot playing every seat.
+func (m *MCTS) playout(engine *game.Engine) {
+	for depth := 0; depth < m.config.RolloutDepth; depth++ {
+		mover := engine.CurrentPlayerID()
+		if mover == "" {
+			return
+		}
+		if _, err := engine.Apply(mover, m.fallback.Choose(engine.Snapshot(), mover)); err != nil {
+			return
+		}
+	}
+}
+
+// evaluate scores a position in [0, 1] for every player: winners of a
+// finished game get 1 and losers 0. Unfinished games compare players on
+// points with a little credit for cards and tokens, kept strictly inside
+// (0, 1) so a certain win always beats a merely good position.
+func evaluate(state game.State) map[string]float64 {
+	out := make(map[string]float64, len(state.Players))
+	if state.Status == game.StatusFinished {
+		for _, id := range state.WinnerIDs {
+			out[id] = 1
+		}
+		return out
+	}
+
+	values := make(map[string]float64, len(state.Players))
+	for _, p := range state.Players {
+		values[p.ID] = float64(p.Points) + 0.3*float64(p.PurchasedCount) + 0.05*float64(p.Tokens.Total())
+	}
+	target := float64(max(state.Rules.TargetScore, 1))
+	for _, p := range state.Players {
+		bestOther := math.Inf(-1)
+		for id, v := range values {
+			if id != p.ID && v > bestOther {
+				bestOther = v
+			}
+		}
+		lead := math.Max(-1, math.Min(1, (values[p.ID]-bestOther)/target))
+		out[p.ID] = 0.5 + 0.4*lead
+	}
+	return out
+}
+
+// actionKey identifies an action across determinizations.
+func actionKey(action game.Action) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%d|%s",
+		action.Type, strings.Join(action.Payload.Colors, ","),
+		action.Payload.CardID, action.Payload.Source, action.Payload.Tier, action.Payload.NobleID)
+}
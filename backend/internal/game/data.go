@@ -1,6 +1,13 @@
 package game
 
-import "math/rand"
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+var ErrInvalidCardSet = errors.New("invalid card set")
 
 func cardsDataset() []Card {
 	return []Card{
@@ -112,8 +119,57 @@ func noblesDataset() []Noble {
 	}
 }
 
-func initDecks() (deck1 []Card, deck2 []Card, deck3 []Card) {
-	for _, c := range cardsDataset() {
+// Cards returns every development card in the game.
+func Cards() []Card {
+	return cardsDataset()
+}
+
+// ValidateCards checks a custom card set: every card needs a unique ID, a
+// tier from 1 to 3, a colored bonus, non-negative points and a cost in
+// colored tokens only.
+func ValidateCards(cards []Card) error {
+	if len(cards) == 0 {
+		return fmt.Errorf("%w: no cards", ErrInvalidCardSet)
+	}
+	ids := make(map[string]bool, len(cards))
+	for _, c := range cards {
+		switch {
+		case c.ID == "":
+			return fmt.Errorf("%w: card without id", ErrInvalidCardSet)
+		case ids[c.ID]:
+			return fmt.Errorf("%w: duplicate card %q", ErrInvalidCardSet, c.ID)
+		case c.Tier < 1 || c.Tier > 3:
+			return fmt.Errorf("%w: card %q has tier %d", ErrInvalidCardSet, c.ID, c.Tier)
+		case !slices.Contains(ColoredGems, c.Bonus):
+			return fmt.Errorf("%w: card %q has bonus %q", ErrInvalidCardSet, c.ID, c.Bonus)
+		case c.Points < 0:
+			return fmt.Errorf("%w: card %q has negative points", ErrInvalidCardSet, c.ID)
+		case c.Blind:
+			return fmt.Errorf("%w: card %q is marked blind", ErrInvalidCardSet, c.ID)
+		}
+		for _, color := range ColoredGems {
+			if c.Cost.Get(color) < 0 {
+				return fmt.Errorf("%w: card %q has a negative %s cost", ErrInvalidCardSet, c.ID, color)
+			}
+		}
+		if c.Cost.Gold != 0 {
+			return fmt.Errorf("%w: card %q costs gold", ErrInvalidCardSet, c.ID)
+		}
+		ids[c.ID] = true
+	}
+	return nil
+}
+
+// cards is the card set the game was dealt from.
+func (s State) cards() []Card {
+	if s.CardSet != nil {
+		return s.CardSet
+	}
+	return cardsDataset()
+}
+
+func initDecks(rng *rand.Rand, cards []Card) (deck1 []Card, deck2 []Card, deck3 []Card) {
+	for _, c := range cards {
 		switch c.Tier {
 		case 1:
 			deck1 = append(deck1, c)
@@ -124,9 +180,9 @@ func initDecks() (deck1 []Card, deck2 []Card, deck3 []Card) {
 		}
 	}
 
-	rand.Shuffle(len(deck1), func(i, j int) { deck1[i], deck1[j] = deck1[j], deck1[i] })
-	rand.Shuffle(len(deck2), func(i, j int) { deck2[i], deck2[j] = deck2[j], deck2[i] })
-	rand.Shuffle(len(deck3), func(i, j int) { deck3[i], deck3[j] = deck3[j], deck3[i] })
+	rng.Shuffle(len(deck1), func(i, j int) { deck1[i], deck1[j] = deck1[j], deck1[i] })
+	rng.Shuffle(len(deck2), func(i, j int) { deck2[i], deck2[j] = deck2[j], deck2[i] })
+	rng.Shuffle(len(deck3), func(i, j int) { deck3[i], deck3[j] = deck3[j], deck3[i] })
 	return deck1, deck2, deck3
 }
 
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Determinize builds an engine consistent with what viewerID sees in state.
+// Cards the viewer has not seen — the deck contents and other players' blind
+// reservations, which RedactedFor reduces to their tier — are filled in with
+// a random assignment drawn with rng. Search bots play out such samples in
+// place of the real, hidden deck order. The state may be redacted or not;
+// only what the viewer could see is used.
+func Determinize(state State, viewerID string, rng *rand.Rand) (*Engine, error) {
+	s := (&Engine{state: &state}).Snapshot()
+
+	seen := make(map[string]bool)
+	for _, tier := range [][]Card{s.Tier1, s.Tier2, s.Tier3} {
+		for _, c := range tier {
+			seen[c.ID] = true
+		}
+	}
+	for _, p := range s.Players {
+		for _, id := range p.PurchasedIDs {
+			seen[id] = true
+		}
+		for _, c := range p.Reserved {
+			if p.ID == viewerID || !c.Blind {
+				seen[c.ID] = true
+			}
+		}
+	}
+
+	unseen := make([][]Card, 3)
+	for _, c := range s.cards() {
+		if !seen[c.ID] && c.Tier >= 1 && c.Tier <= 3 {
+			unseen[c.Tier-1] = append(unseen[c.Tier-1], c)
+		}
+	}
+	for _, pile := range unseen {
+		rng.Shuffle(len(pile), func(i, j int) { pile[i], pile[j] = pile[j], pile[i] })
+	}
+
+	for i := range s.Players {
+		p := &s.Players[i]
+		if p.ID == viewerID {
+			continue
+		}
+		for j, c := range p.Reserved {
+			if !c.Blind {
+				continue
+			}
+			pile := &unseen[c.Tier-1]
+			if len(*pile) == 0 {
+				return nil, fmt.Errorf("%w: no unseen tier %d card left for a blind reservation", ErrInvariantViolation, c.Tier)
+			}
+			p.Reserved[j] = (*pile)[0]
+			p.Reserved[j].Blind = true
+			*pile = (*pile)[1:]
+		}
+	}
+
+	for i, count := range []int{s.Deck1Count, s.Deck2Count, s.Deck3Count} {
+		if len(unseen[i]) != count {
+			return nil, fmt.Errorf("%w: %d unseen tier %d cards for a deck of %d", ErrInvariantViolation, len(unseen[i]), i+1, count)
+		}
+	}
+
+	return &Engine{
+		state: &s,
+		deck1: unseen[0],
+		deck2: unseen[1],
+		deck3: unseen[2],
+	}, nil
+}
+
+// Resample redraws every card viewerID cannot see — the deck contents and
+// other players' blind reservations — from the cards now in those places, so
+// each tier keeps its hidden cards but they land in a new random order. Search
+// bots call it on a Clone of a determinized engine to draw a fresh sample
+// without building a new engine.
+func (e *Engine) Resample(viewerID string, rng *rand.Rand) {
+	decks := []*[]Card{&e.deck1, &e.deck2, &e.deck3}
+	pools := make([][]Card, 3)
+	for i, deck := range decks {
+		pools[i] = append(pools[i], *deck...)
+	}
+	var blind []*Card
+	for i := range e.state.Players {
+		p := &e.state.Players[i]
+		if p.ID == viewerID {
+			continue
+		}
+		for j := range p.Reserved {
+			if c := &p.Reserved[j]; c.Blind {
+				pools[c.Tier-1] = append(pools[c.Tier-1], *c)
+				blind = append(blind, c)
+			}
+		}
+	}
+	for _, pool := range pools {
+		rng.Shuffle(len(pool), func(i, j int) { pool[i], pool[j] = pool[j], pool[i] })
+	}
+
+	for _, c := range blind {
+		pool := &pools[c.Tier-1]
+		*c = (*pool)[0]
+		c.Blind = true
+		*pool = (*pool)[1:]
+	}
+	for i, deck := range decks {
+		*deck = pools[i]
+	}
+}
@@ -15,24 +15,79 @@ var (
 	ErrGameFinished       = errors.New("game already finished")
 	ErrUnknownAction      = errors.New("unknown action")
 	ErrInvalidAction      = errors.New("invalid action")
+	ErrReplayMismatch     = errors.New("replay diverged from log")
+	ErrInvalidMode        = errors.New("invalid game mode")
 )
 
 type Engine struct {
 	state *State
-	deck1 []Card
-	deck2 []Card
-	deck3 []Card
+	seed  int64
+	log   []LogEntry
+	// verify runs CheckInvariants after each action; fault keeps the first
+	// violation so a corrupted game refuses further moves.
+	verify bool
+	fault  error
+	// events collects what the action being applied changed.
+	events []Event
+	deck1  []Card
+	deck2  []Card
+	deck3  []Card
 }
 
+// Options controls how a new game is set up.
+type Options struct {
+	// Seed drives deck and noble shuffling. Games created with the same
+	// seeds and seats start from identical deck and noble order.
+	Seed int64
+	// Rules selects the variant; the zero value means DefaultRules.
+	Rules RuleSet
+	// Mode is ModeStrict or ModeSandbox; empty means sandbox.
+	Mode string
+	// Cards replaces the standard deck with a custom card set, which must
+	// pass ValidateCards; nil deals the standard 90 cards.
+	Cards []Card
+	// CheckInvariants verifies the whole state after every action.
+	CheckInvariants bool
+}
+
+// New starts a game with a time-based seed.
 func New(seats []Seat) (*Engine, error) {
+	return NewWithOptions(seats, Options{Seed: time.Now().UnixNano()})
+}
+
+// NewWithSeed starts a game whose setup is fully determined by seed.
+func NewWithSeed(seats []Seat, seed int64) (*Engine, error) {
+	return NewWithOptions(seats, Options{Seed: seed})
+}
+
+func NewWithOptions(seats []Seat, opts Options) (*Engine, error) {
 	if len(seats) < 2 || len(seats) > 4 {
 		return nil, ErrInvalidPlayerCount
 	}
+	rules := opts.Rules
+	if rules == (RuleSet{}) {
+		rules = DefaultRules()
+	}
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+	mode, err := NormalizeMode(opts.Mode)
+	if err != nil {
+		return nil, err
+	}
 
-	rand.Seed(time.Now().UnixNano())
-	deck1, deck2, deck3 := initDecks()
+	cards := cardsDataset()
+	if opts.Cards != nil {
+		if err := ValidateCards(opts.Cards); err != nil {
+			return nil, err
+		}
+		cards = append([]Card(nil), opts.Cards...)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	deck1, deck2, deck3 := initDecks(rng, cards)
 	nobles := noblesDataset()
-	rand.Shuffle(len(nobles), func(i, j int) { nobles[i], nobles[j] = nobles[j], nobles[i] })
+	rng.Shuffle(len(nobles), func(i, j int) { nobles[i], nobles[j] = nobles[j], nobles[i] })
 
 	players := make([]PlayerState, 0, len(seats))
 	for _, seat := range seats {
@@ -43,7 +98,7 @@ func New(seats []Seat) (*Engine, error) {
 		})
 	}
 
-	bankCount := tokenCountByPlayers(len(seats))
+	bankCount := rules.bankTokens(len(seats))
 	state := &State{
 		Status:          StatusPlaying,
 		Turn:            1,
@@ -54,49 +109,134 @@ func New(seats []Seat) (*Engine, error) {
 			Green: bankCount,
 			Red:   bankCount,
 			Black: bankCount,
-			Gold:  5,
+			Gold:  rules.GoldCount,
 		},
 		Tier1: append([]Card(nil), draw(&deck1, 4)...),
 		Tier2: append([]Card(nil), draw(&deck2, 4)...),
 		Tier3: append([]Card(nil), draw(&deck3, 4)...),
 		Nobles: append([]Noble(nil),
-			nobles[:len(seats)+1]...,
+			nobles[:len(seats)+rules.ExtraNobles]...,
 		),
 		Players: players,
+		Phase:   PhaseAction,
+		Rules:   rules,
+		Mode:    mode,
+	}
+	if opts.Cards != nil {
+		state.CardSet = cards
 	}
 	state.Deck1Count = len(deck1)
 	state.Deck2Count = len(deck2)
 	state.Deck3Count = len(deck3)
 
-	return &Engine{state: state, deck1: deck1, deck2: deck2, deck3: deck3}, nil
+	return &Engine{
+		state:  state,
+		seed:   opts.Seed,
+		verify: opts.CheckInvariants,
+		deck1:  deck1,
+		deck2:  deck2,
+		deck3:  deck3,
+	}, nil
 }
 
-func tokenCountByPlayers(n int) int {
-	switch n {
-	case 2:
-		return 4
-	case 3:
-		return 5
+// NormalizeMode validates a game mode name. Empty selects sandbox, which keeps
+// the table-top freedom of adjusting tokens and passing at will.
+func NormalizeMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", ModeSandbox:
+		return ModeSandbox, nil
+	case ModeStrict:
+		return ModeStrict, nil
 	default:
-		return 7
+		return "", fmt.Errorf("%w: %q", ErrInvalidMode, mode)
 	}
 }
 
+// Seed returns the seed the game was set up with.
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
 func (e *Engine) Snapshot() State {
 	copy := *e.state
 	copy.Players = append([]PlayerState(nil), e.state.Players...)
 	for i := range copy.Players {
 		copy.Players[i].Reserved = append([]Card(nil), e.state.Players[i].Reserved...)
 		copy.Players[i].Nobles = append([]Noble(nil), e.state.Players[i].Nobles...)
+		copy.Players[i].PurchasedIDs = append([]string(nil), e.state.Players[i].PurchasedIDs...)
 	}
 	copy.Tier1 = append([]Card(nil), e.state.Tier1...)
 	copy.Tier2 = append([]Card(nil), e.state.Tier2...)
 	copy.Tier3 = append([]Card(nil), e.state.Tier3...)
 	copy.Nobles = append([]Noble(nil), e.state.Nobles...)
 	copy.WinnerIDs = append([]string(nil), e.state.WinnerIDs...)
+	if e.state.Pending != nil {
+		pending := *e.state.Pending
+		pending.NobleIDs = append([]string(nil), e.state.Pending.NobleIDs...)
+		copy.Pending = &pending
+	}
 	return copy
 }
 
+// Clone returns an independent copy of the engine, hidden decks included,
+// without going through serialization. Search code clones an engine for
+// every line of play it explores.
+func (e *Engine) Clone() *Engine {
+	state := e.Snapshot()
+	out := *e
+	out.state = &state
+	out.deck1 = append([]Card(nil), e.deck1...)
+	out.deck2 = append([]Card(nil), e.deck2...)
+	out.deck3 = append([]Card(nil), e.deck3...)
+	// Recorded entries are never modified, so the clone shares them; capping
+	// the slice makes an append on either side copy instead of overwrite.
+	out.log = e.log[:len(e.log):len(e.log)]
+	return &out
+}
+
+// CurrentPlayerID is the player on turn, or empty once the game is over.
+func (e *Engine) CurrentPlayerID() string {
+	if e.state.Status == StatusFinished {
+		return ""
+	}
+	return e.state.CurrentPlayerID
+}
+
+// RedactedFor returns a copy of the state as seen by viewerID: blind
+// reservations of other players are reduced to their tier.
+func (s State) RedactedFor(viewerID string) State {
+	out := s
+	out.Players = append([]PlayerState(nil), s.Players...)
+	for i := range out.Players {
+		p := &out.Players[i]
+		if p.ID == viewerID {
+			continue
+		}
+		reserved := make([]Card, len(p.Reserved))
+		for j, c := range p.Reserved {
+			if c.Blind {
+				c = Card{Tier: c.Tier, Blind: true}
+			}
+			reserved[j] = c
+		}
+		p.Reserved = reserved
+	}
+	return out
+}
+
+// RedactEvents returns events as viewerID may see them: cards other players
+// reserved blind are reduced to their tier.
+func RedactEvents(events []Event, viewerID string) []Event {
+	out := make([]Event, len(events))
+	for i, ev := range events {
+		if ev.Type == EventCardReserved && ev.Card != nil && ev.Card.Blind && ev.PlayerID != viewerID {
+			ev.Card = &Card{Tier: ev.Card.Tier, Blind: true}
+		}
+		out[i] = ev
+	}
+	return out
+}
+
 func (e *Engine) SetConnected(playerID string, connected bool) {
 	idx := e.playerIndex(playerID)
 	if idx == -1 {
@@ -105,15 +245,68 @@ func (e *Engine) SetConnected(playerID string, connected bool) {
 	e.state.Players[idx].IsConnected = connected
 }
 
-func (e *Engine) Apply(playerID string, action Action) error {
+// Log returns every accepted action in the order it was applied.
+func (e *Engine) Log() []LogEntry {
+	out := make([]LogEntry, len(e.log))
+	for i, entry := range e.log {
+		out[i] = cloneLogEntry(entry)
+	}
+	return out
+}
+
+// Apply plays an action and returns the events it caused.
+func (e *Engine) Apply(playerID string, action Action) ([]Event, error) {
+	return e.apply(playerID, action, time.Now().UTC(), false)
+}
+
+// apply runs one action and, when enabled, verifies the resulting state.
+func (e *Engine) apply(playerID string, action Action, at time.Time, timeout bool) ([]Event, error) {
+	if e.fault != nil {
+		return nil, e.fault
+	}
+	e.events = nil
+	defer func() { e.events = nil }()
+	if err := e.step(playerID, action, at, timeout); err != nil {
+		return nil, err
+	}
+	if e.verify {
+		if err := CheckInvariants(*e.state, e.hidden()); err != nil {
+			e.fault = err
+			return nil, err
+		}
+	}
+	return e.events, nil
+}
+
+func (e *Engine) emit(event Event) {
+	e.events = append(e.events, event)
+}
+
+// step runs one action. Timeout moves are played on the player's behalf and
+// skip the strict-mode check on passing.
+func (e *Engine) step(playerID string, action Action, at time.Time, timeout bool) error {
 	if e.state.Status == StatusFinished {
 		return ErrGameFinished
 	}
+	actionType := strings.ToLower(strings.TrimSpace(action.Type))
+	if actionType == "resign" {
+		return e.applyResign(playerID, action, at, timeout)
+	}
 	if e.state.CurrentPlayerID != playerID {
 		return ErrNotPlayerTurn
 	}
 
-	switch strings.ToLower(strings.TrimSpace(action.Type)) {
+	before := e.capture(playerID)
+	if pending := e.state.Pending; pending != nil && actionType != pending.Action {
+		return fmt.Errorf("%w: %s is required before the turn can end", ErrInvalidAction, pending.Action)
+	}
+	if e.state.Mode == ModeStrict {
+		if err := e.checkStrict(playerID, actionType, timeout); err != nil {
+			return err
+		}
+	}
+
+	switch actionType {
 	case "take_tokens":
 		if err := e.applyTakeTokens(playerID, action.Payload.Colors); err != nil {
 			return err
@@ -123,15 +316,25 @@ func (e *Engine) Apply(playerID string, action Action) error {
 			return err
 		}
 	case "discard_tokens":
+		if e.state.Pending != nil {
+			if err := e.resolveDiscard(playerID, action.Payload.Colors); err != nil {
+				return err
+			}
+			break
+		}
 		if err := e.applyDiscardTokens(playerID, action.Payload.Colors); err != nil {
 			return err
 		}
 	case "reserve_card":
-		if err := e.applyReserveCard(playerID, action.Payload.CardID); err != nil {
+		if err := e.applyReserveCard(playerID, action.Payload); err != nil {
 			return err
 		}
 	case "buy_card":
-		if err := e.applyBuyCard(playerID, action.Payload.CardID, action.Payload.Source); err != nil {
+		if err := e.applyBuyCard(playerID, action.Payload); err != nil {
+			return err
+		}
+	case "claim_noble":
+		if err := e.applyClaimNoble(playerID, action.Payload.NobleID); err != nil {
 			return err
 		}
 	case "pass":
@@ -140,10 +343,179 @@ func (e *Engine) Apply(playerID string, action Action) error {
 		return ErrUnknownAction
 	}
 
-	e.endTurn(playerID, action.Type)
+	if e.awaitDiscardIfOverLimit(playerID, action.Type) {
+		e.record(before, action, at, timeout)
+		return nil
+	}
+	if actionType != "claim_noble" && e.awaitNobleChoice(playerID, action.Type) {
+		e.record(before, action, at, timeout)
+		return nil
+	}
+	e.endTurn(playerID, e.closePending(playerID, action.Type))
+	e.record(before, action, at, timeout)
+	return nil
+}
+
+// ApplyTimeout plays the move a player makes when their turn timer runs out:
+// a pending decision is resolved automatically, otherwise the turn is passed.
+// Timeout passes are allowed in strict mode.
+func (e *Engine) ApplyTimeout(playerID string) ([]Event, error) {
+	return e.ApplyTimeoutAction(playerID, e.timeoutAction(playerID))
+}
+
+// ApplyTimeoutAction plays action on behalf of a player whose turn timer ran
+// out, for callers that pick the move themselves, such as a bot standing in.
+// The move is logged as a timeout move.
+func (e *Engine) ApplyTimeoutAction(playerID string, action Action) ([]Event, error) {
+	return e.apply(playerID, action, time.Now().UTC(), true)
+}
+
+// checkStrict rejects the sandbox freedoms in strict games: free-form token
+// adjustment, voluntary discards, and passing while another legal move
+// exists.
+func (e *Engine) checkStrict(playerID, actionType string, timeout bool) error {
+	switch actionType {
+	case "adjust_tokens":
+		return fmt.Errorf("%w: adjust_tokens is disabled in strict mode", ErrInvalidAction)
+	case "discard_tokens":
+		if e.state.Pending == nil || e.state.Pending.Action != "discard_tokens" {
+			return fmt.Errorf("%w: discard_tokens is only allowed over the token limit in strict mode", ErrInvalidAction)
+		}
+	case "pass":
+		if timeout {
+			return nil
+		}
+		if legal := e.LegalActions(playerID); len(legal) > 0 && legal[0].Type != "pass" {
+			return fmt.Errorf("%w: cannot pass while other moves are available", ErrInvalidAction)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) timeoutAction(playerID string) Action {
+	pending := e.state.Pending
+	if pending == nil || pending.PlayerID != playerID {
+		return Action{Type: "pass"}
+	}
+	if pending.Action == "claim_noble" && len(pending.NobleIDs) > 0 {
+		// Candidates follow the board order, so the pick is deterministic.
+		return Action{Type: "claim_noble", Payload: ActionInput{NobleID: pending.NobleIDs[0]}}
+	}
+	if pending.Action != "discard_tokens" {
+		return Action{Type: "pass"}
+	}
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return Action{Type: "pass"}
+	}
+
+	// Give back from the largest colored stack first and keep gold longest.
+	tokens := e.state.Players[idx].Tokens
+	colors := make([]string, 0, pending.DiscardCount)
+	for len(colors) < pending.DiscardCount {
+		pick := ""
+		for _, color := range ColoredGems {
+			if tokens.Get(color) > 0 && (pick == "" || tokens.Get(color) > tokens.Get(pick)) {
+				pick = color
+			}
+		}
+		if pick == "" {
+			pick = GemGold
+		}
+		tokens.Sub(pick, 1)
+		colors = append(colors, pick)
+	}
+	return Action{Type: "discard_tokens", Payload: ActionInput{Colors: colors}}
+}
+
+// awaitDiscardIfOverLimit leaves the turn open with a pending discard when a
+// take or reserve pushed the player above the token limit.
+func (e *Engine) awaitDiscardIfOverLimit(playerID, actionType string) bool {
+	switch strings.ToLower(strings.TrimSpace(actionType)) {
+	case "take_tokens", "reserve_card":
+	default:
+		return false
+	}
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return false
+	}
+	p := &e.state.Players[idx]
+	over := p.Tokens.Total() - e.state.Rules.TokenLimit
+	if over <= 0 {
+		return false
+	}
+
+	p.LastAction = actionType
+	e.state.Phase = PhaseAwaitingDiscard
+	e.state.Pending = &Pending{PlayerID: playerID, Action: "discard_tokens", DiscardCount: over}
+	return true
+}
+
+// awaitNobleChoice runs the end-of-turn noble visit. A single qualifying
+// noble is claimed right away; when several qualify the turn stays open until
+// the player picks one with claim_noble.
+func (e *Engine) awaitNobleChoice(playerID, actionType string) bool {
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return false
+	}
+	p := &e.state.Players[idx]
+
+	candidates := make([]string, 0)
+	for _, n := range e.state.Nobles {
+		if hasNobleRequirement(p.Bonuses, n.Requirement) {
+			candidates = append(candidates, n.ID)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return false
+	case 1:
+		e.claimNoble(p, candidates[0])
+		return false
+	}
+
+	if e.state.Phase == PhaseAction {
+		p.LastAction = actionType
+	}
+	e.state.Phase = PhaseChooseNoble
+	e.state.Pending = &Pending{PlayerID: playerID, Action: "claim_noble", NobleIDs: candidates}
+	return true
+}
+
+func (e *Engine) applyClaimNoble(playerID, nobleID string) error {
+	pending := e.state.Pending
+	if pending == nil || pending.Action != "claim_noble" {
+		return fmt.Errorf("%w: no noble to claim", ErrInvalidAction)
+	}
+	nobleID = strings.TrimSpace(nobleID)
+	if !slices.Contains(pending.NobleIDs, nobleID) {
+		return fmt.Errorf("%w: noble %s is not a candidate", ErrInvalidAction, nobleID)
+	}
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return ErrInvalidAction
+	}
+	e.claimNoble(&e.state.Players[idx], nobleID)
 	return nil
 }
 
+// closePending clears a resolved pending decision and returns the action to
+// record as the player's last action: the move that opened the decision, or
+// actionType when there was none.
+func (e *Engine) closePending(playerID, actionType string) string {
+	if e.state.Phase == PhaseAction {
+		return actionType
+	}
+	e.state.Phase = PhaseAction
+	e.state.Pending = nil
+	if idx := e.playerIndex(playerID); idx != -1 {
+		return e.state.Players[idx].LastAction
+	}
+	return actionType
+}
+
 func (e *Engine) applyTakeTokens(playerID string, colors []string) error {
 	idx := e.playerIndex(playerID)
 	if idx == -1 {
@@ -163,11 +535,6 @@ func (e *Engine) applyTakeTokens(playerID string, colors []string) error {
 	}
 
 	p := &e.state.Players[idx]
-	projected := p.Tokens.Total() + len(normalized)
-	if projected > 10 {
-		return fmt.Errorf("%w: token limit exceeded", ErrInvalidAction)
-	}
-
 	if len(normalized) == 2 && normalized[0] == normalized[1] {
 		color := normalized[0]
 		if e.state.Bank.Get(color) < 4 {
@@ -175,6 +542,9 @@ func (e *Engine) applyTakeTokens(playerID string, colors []string) error {
 		}
 		e.state.Bank.Sub(color, 2)
 		p.Tokens.Add(color, 2)
+		taken := TokenSet{}
+		taken.Add(color, 2)
+		e.emit(Event{Type: EventTokensTaken, PlayerID: playerID, Tokens: &taken})
 		return nil
 	}
 
@@ -193,10 +563,13 @@ func (e *Engine) applyTakeTokens(playerID string, colors []string) error {
 		}
 	}
 
+	taken := TokenSet{}
 	for _, c := range normalized {
 		e.state.Bank.Sub(c, 1)
 		p.Tokens.Add(c, 1)
+		taken.Add(c, 1)
 	}
+	e.emit(Event{Type: EventTokensTaken, PlayerID: playerID, Tokens: &taken})
 	return nil
 }
 
@@ -226,13 +599,25 @@ func (e *Engine) applyDiscardTokens(playerID string, colors []string) error {
 		}
 	}
 
+	discarded := TokenSet{}
 	for color, giveBack := range counts {
 		p.Tokens.Sub(color, giveBack)
 		e.state.Bank.Add(color, giveBack)
+		discarded.Add(color, giveBack)
 	}
+	e.emit(Event{Type: EventTokensDiscarded, PlayerID: playerID, Tokens: &discarded})
 	return nil
 }
 
+// resolveDiscard settles a pending over-limit discard. Exactly the pending
+// number of tokens must be returned.
+func (e *Engine) resolveDiscard(playerID string, colors []string) error {
+	if len(colors) != e.state.Pending.DiscardCount {
+		return fmt.Errorf("%w: must discard exactly %d tokens", ErrInvalidAction, e.state.Pending.DiscardCount)
+	}
+	return e.applyDiscardTokens(playerID, colors)
+}
+
 func (e *Engine) applyAdjustTokens(playerID string, adjust map[string]int) error {
 	idx := e.playerIndex(playerID)
 	if idx == -1 {
@@ -271,14 +656,16 @@ func (e *Engine) applyAdjustTokens(playerID string, adjust map[string]int) error
 		net += delta
 	}
 
-	if p.Tokens.Total()+net > 10 {
+	if p.Tokens.Total()+net > e.state.Rules.TokenLimit {
 		return fmt.Errorf("%w: token limit exceeded", ErrInvalidAction)
 	}
 
+	changed := TokenSet{}
 	for color, delta := range adjust {
 		if delta == 0 {
 			continue
 		}
+		changed.Add(color, delta)
 		if delta > 0 {
 			e.state.Bank.Sub(color, delta)
 			p.Tokens.Add(color, delta)
@@ -288,100 +675,186 @@ func (e *Engine) applyAdjustTokens(playerID string, adjust map[string]int) error
 			e.state.Bank.Add(color, back)
 		}
 	}
+	e.emit(Event{Type: EventTokensAdjusted, PlayerID: playerID, Tokens: &changed})
 	return nil
 }
 
-func (e *Engine) applyReserveCard(playerID, cardID string) error {
+func (e *Engine) applyReserveCard(playerID string, input ActionInput) error {
 	idx := e.playerIndex(playerID)
 	if idx == -1 {
 		return ErrInvalidAction
 	}
-	cardID = strings.TrimSpace(cardID)
-	if cardID == "" {
-		return fmt.Errorf("%w: cardId is required", ErrInvalidAction)
-	}
 
 	p := &e.state.Players[idx]
-	if len(p.Reserved) >= 3 {
-		return fmt.Errorf("%w: reserved card limit is 3", ErrInvalidAction)
+	if len(p.Reserved) >= e.state.Rules.ReserveLimit {
+		return fmt.Errorf("%w: reserved card limit is %d", ErrInvalidAction, e.state.Rules.ReserveLimit)
 	}
 
-	card, ok := e.takeTableauCardByID(cardID)
-	if !ok {
-		return fmt.Errorf("%w: card not found in tableau", ErrInvalidAction)
+	// The reservation is reported before the tableau refill it causes.
+	reserved := Event{Type: EventCardReserved, PlayerID: playerID}
+	if e.state.Bank.Gold > 0 {
+		reserved.Tokens = &TokenSet{Gold: 1}
+	}
+	var card Card
+	switch source := strings.ToLower(strings.TrimSpace(input.Source)); source {
+	case "", "tableau":
+		cardID := strings.TrimSpace(input.CardID)
+		if cardID == "" {
+			return fmt.Errorf("%w: cardId is required", ErrInvalidAction)
+		}
+		var ok bool
+		card, ok = e.lookupTableauCard(cardID)
+		if !ok {
+			return fmt.Errorf("%w: card not found in tableau", ErrInvalidAction)
+		}
+		reserved.Card, reserved.Source = &card, "tableau"
+		e.emit(reserved)
+		card, _ = e.takeTableauCardByID(cardID)
+	case "deck":
+		var ok bool
+		card, ok = e.takeDeckTop(input.Tier)
+		if !ok {
+			return fmt.Errorf("%w: deck for tier %d is empty or missing", ErrInvalidAction, input.Tier)
+		}
+		card.Blind = true
+		reserved.Card, reserved.Source = &card, "deck"
+		e.emit(reserved)
+	default:
+		return fmt.Errorf("%w: source must be tableau or deck", ErrInvalidAction)
 	}
 
 	p.Reserved = append(p.Reserved, card)
 
-	if e.state.Bank.Gold > 0 && p.Tokens.Total() < 10 {
+	if e.state.Bank.Gold > 0 {
 		e.state.Bank.Gold--
 		p.Tokens.Gold++
 	}
 	return nil
 }
 
-func (e *Engine) applyBuyCard(playerID, cardID, source string) error {
+func (e *Engine) applyBuyCard(playerID string, input ActionInput) error {
 	idx := e.playerIndex(playerID)
 	if idx == -1 {
 		return ErrInvalidAction
 	}
-	cardID = strings.TrimSpace(cardID)
+	cardID := strings.TrimSpace(input.CardID)
 	if cardID == "" {
 		return fmt.Errorf("%w: cardId is required", ErrInvalidAction)
 	}
-	source = strings.ToLower(strings.TrimSpace(source))
-	if source == "" {
-		source = "tableau"
-	}
+	source := normalizeBuySource(input.Source)
 
 	p := &e.state.Players[idx]
-	var card Card
-	var ok bool
+	card, err := e.lookupBuyCard(p, cardID, source)
+	if err != nil {
+		return err
+	}
 
-	switch source {
-	case "tableau":
-		card, ok = e.takeTableauCardByID(cardID)
-		if !ok {
-			return fmt.Errorf("%w: card not found in tableau", ErrInvalidAction)
+	var payment TokenSet
+	if input.Payment != nil {
+		if err := validatePayment(card.Cost, p.Bonuses, p.Tokens, *input.Payment); err != nil {
+			return err
 		}
-	case "reserved":
-		card, ok = takeReservedCardByID(p, cardID)
-		if !ok {
-			return fmt.Errorf("%w: card not found in reserved", ErrInvalidAction)
+		payment = *input.Payment
+	} else {
+		var canPay bool
+		payment, canPay = calculatePayment(card.Cost, p.Bonuses, p.Tokens)
+		if !canPay {
+			return fmt.Errorf("%w: not enough tokens to buy card", ErrInvalidAction)
 		}
-	default:
-		return fmt.Errorf("%w: source must be tableau or reserved", ErrInvalidAction)
 	}
 
-	payment, canPay := calculatePayment(card.Cost, p.Bonuses, p.Tokens)
-	if !canPay {
-		// restore card if we already removed it.
-		e.restoreCard(card, source, p)
-		return fmt.Errorf("%w: not enough tokens to buy card", ErrInvalidAction)
+	// The card is only taken once payment is settled, so a rejected buy
+	// leaves the tableau and its replacement draw untouched.
+	// Buying a blind reservation shows it to everyone.
+	bought := card
+	bought.Blind = false
+	e.emit(Event{Type: EventCardBought, PlayerID: playerID, Tokens: &payment, Card: &bought, Source: source})
+	if source == "tableau" {
+		card, _ = e.takeTableauCardByID(cardID)
+	} else {
+		card, _ = takeReservedCardByID(p, cardID)
 	}
-
 	applyPayment(&p.Tokens, &e.state.Bank, payment)
 	grantCard(p, card)
-	e.tryClaimNoble(p)
 	return nil
 }
 
-func (e *Engine) restoreCard(card Card, source string, p *PlayerState) {
+// PreviewPurchase reports whether playerID could buy a card right now and
+// what it would cost, without changing the game. It works off turn too.
+func (e *Engine) PreviewPurchase(playerID, cardID, source string) (PurchasePreview, error) {
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return PurchasePreview{}, ErrInvalidAction
+	}
+	cardID = strings.TrimSpace(cardID)
+	if cardID == "" {
+		return PurchasePreview{}, fmt.Errorf("%w: cardId is required", ErrInvalidAction)
+	}
+	source = normalizeBuySource(source)
+
+	p := e.state.Players[idx]
+	card, err := e.lookupBuyCard(&p, cardID, source)
+	if err != nil {
+		return PurchasePreview{}, err
+	}
+
+	preview := PurchasePreview{CardID: card.ID, Source: source}
+	if payment, ok := calculatePayment(card.Cost, p.Bonuses, p.Tokens); ok {
+		preview.Affordable = true
+		preview.Payment = payment
+	}
+	for _, color := range ColoredGems {
+		need := max(card.Cost.Get(color)-p.Bonuses.Get(color), 0)
+		preview.Shortfall.Add(color, max(need-p.Tokens.Get(color), 0))
+	}
+	preview.Shortfall.Gold = max(preview.Shortfall.Total()-p.Tokens.Gold, 0)
+	return preview, nil
+}
+
+func normalizeBuySource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if source == "" {
+		return "tableau"
+	}
+	return source
+}
+
+// lookupBuyCard finds a card a player may buy without removing it.
+func (e *Engine) lookupBuyCard(p *PlayerState, cardID, source string) (Card, error) {
 	switch source {
 	case "tableau":
-		switch card.Tier {
-		case 1:
-			e.state.Tier1 = append(e.state.Tier1, card)
-		case 2:
-			e.state.Tier2 = append(e.state.Tier2, card)
-		case 3:
-			e.state.Tier3 = append(e.state.Tier3, card)
+		if card, ok := e.lookupTableauCard(cardID); ok {
+			return card, nil
 		}
+		return Card{}, fmt.Errorf("%w: card not found in tableau", ErrInvalidAction)
 	case "reserved":
-		p.Reserved = append(p.Reserved, card)
+		if card, ok := findCard(p.Reserved, cardID); ok {
+			return card, nil
+		}
+		return Card{}, fmt.Errorf("%w: card not found in reserved", ErrInvalidAction)
+	default:
+		return Card{}, fmt.Errorf("%w: source must be tableau or reserved", ErrInvalidAction)
 	}
 }
 
+func (e *Engine) lookupTableauCard(cardID string) (Card, bool) {
+	for _, tier := range [][]Card{e.state.Tier1, e.state.Tier2, e.state.Tier3} {
+		if card, ok := findCard(tier, cardID); ok {
+			return card, true
+		}
+	}
+	return Card{}, false
+}
+
+func findCard(cards []Card, cardID string) (Card, bool) {
+	for _, c := range cards {
+		if c.ID == cardID {
+			return c, true
+		}
+	}
+	return Card{}, false
+}
+
 func calculatePayment(cost TokenSet, bonuses TokenSet, tokens TokenSet) (TokenSet, bool) {
 	payment := TokenSet{}
 	goldNeed := 0
@@ -407,6 +880,30 @@ func calculatePayment(cost TokenSet, bonuses TokenSet, tokens TokenSet) (TokenSe
 	return payment, true
 }
 
+// validatePayment checks a player-chosen payment: each color pays at most
+// what the card still needs after bonuses, and gold covers exactly the rest.
+func validatePayment(cost TokenSet, bonuses TokenSet, tokens TokenSet, pay TokenSet) error {
+	goldNeed := 0
+	for _, color := range ColoredGems {
+		need := max(cost.Get(color)-bonuses.Get(color), 0)
+		n := pay.Get(color)
+		if n < 0 || n > tokens.Get(color) {
+			return fmt.Errorf("%w: not enough %s tokens for payment", ErrInvalidAction, color)
+		}
+		if n > need {
+			return fmt.Errorf("%w: payment overpays %s", ErrInvalidAction, color)
+		}
+		goldNeed += need - n
+	}
+	if pay.Gold < 0 || pay.Gold > tokens.Gold {
+		return fmt.Errorf("%w: not enough gold tokens for payment", ErrInvalidAction)
+	}
+	if pay.Gold != goldNeed {
+		return fmt.Errorf("%w: payment needs exactly %d gold", ErrInvalidAction, goldNeed)
+	}
+	return nil
+}
+
 func applyPayment(player *TokenSet, bank *TokenSet, pay TokenSet) {
 	for _, color := range append(append([]string(nil), ColoredGems...), GemGold) {
 		n := pay.Get(color)
@@ -420,6 +917,7 @@ func applyPayment(player *TokenSet, bank *TokenSet, pay TokenSet) {
 
 func grantCard(p *PlayerState, card Card) {
 	p.PurchasedCount++
+	p.PurchasedIDs = append(p.PurchasedIDs, card.ID)
 	p.Points += card.Points
 	p.Bonuses.Add(card.Bonus, 1)
 }
@@ -441,21 +939,52 @@ func (e *Engine) takeTableauCardByID(cardID string) (Card, bool) {
 		return Card{}, false
 	}
 
-	if c, ok := takeCardFromPile(&e.state.Tier1, &e.deck1, cardID); ok {
-		e.state.Deck1Count = len(e.deck1)
-		return c, true
-	}
-	if c, ok := takeCardFromPile(&e.state.Tier2, &e.deck2, cardID); ok {
-		e.state.Deck2Count = len(e.deck2)
-		return c, true
+	piles := []struct {
+		tableau *[]Card
+		deck    *[]Card
+		count   *int
+	}{
+		{&e.state.Tier1, &e.deck1, &e.state.Deck1Count},
+		{&e.state.Tier2, &e.deck2, &e.state.Deck2Count},
+		{&e.state.Tier3, &e.deck3, &e.state.Deck3Count},
 	}
-	if c, ok := takeCardFromPile(&e.state.Tier3, &e.deck3, cardID); ok {
-		e.state.Deck3Count = len(e.deck3)
+	for _, pile := range piles {
+		c, ok := takeCardFromPile(pile.tableau, pile.deck, cardID)
+		if !ok {
+			continue
+		}
+		if len(*pile.deck) < *pile.count {
+			revealed := (*pile.tableau)[len(*pile.tableau)-1]
+			e.emit(Event{Type: EventCardRevealed, Card: &revealed, Source: "deck"})
+		}
+		*pile.count = len(*pile.deck)
 		return c, true
 	}
 	return Card{}, false
 }
 
+// takeDeckTop draws the top card of a tier deck without touching the tableau.
+func (e *Engine) takeDeckTop(tier int) (Card, bool) {
+	var deck *[]Card
+	var count *int
+	switch tier {
+	case 1:
+		deck, count = &e.deck1, &e.state.Deck1Count
+	case 2:
+		deck, count = &e.deck2, &e.state.Deck2Count
+	case 3:
+		deck, count = &e.deck3, &e.state.Deck3Count
+	default:
+		return Card{}, false
+	}
+	drawn := draw(deck, 1)
+	if len(drawn) == 0 {
+		return Card{}, false
+	}
+	*count = len(*deck)
+	return drawn[0], true
+}
+
 func takeCardFromPile(tableau *[]Card, deck *[]Card, cardID string) (Card, bool) {
 	for i := range *tableau {
 		if (*tableau)[i].ID == cardID {
@@ -471,13 +1000,14 @@ func takeCardFromPile(tableau *[]Card, deck *[]Card, cardID string) (Card, bool)
 	return Card{}, false
 }
 
-func (e *Engine) tryClaimNoble(p *PlayerState) {
+func (e *Engine) claimNoble(p *PlayerState, nobleID string) {
 	for i := range e.state.Nobles {
 		n := e.state.Nobles[i]
-		if hasNobleRequirement(p.Bonuses, n.Requirement) {
+		if n.ID == nobleID {
 			p.Nobles = append(p.Nobles, n)
 			p.Points += n.Points
 			e.state.Nobles = append(e.state.Nobles[:i], e.state.Nobles[i+1:]...)
+			e.emit(Event{Type: EventNobleClaimed, PlayerID: p.ID, Noble: &n})
 			return
 		}
 	}
@@ -499,9 +1029,10 @@ func (e *Engine) endTurn(playerID, actionType string) {
 	}
 	e.state.Players[idx].LastAction = actionType
 
-	if !e.state.FinalRound && e.state.Players[idx].Points >= 15 {
+	if !e.state.FinalRound && e.state.Players[idx].Points >= e.state.Rules.TargetScore {
 		e.state.FinalRound = true
-		e.state.FinalTurnsLeft = len(e.state.Players) - 1
+		e.state.FinalTurnsLeft = e.activePlayers() - 1
+		e.emit(Event{Type: EventFinalRoundStarted, PlayerID: playerID})
 	} else if e.state.FinalRound {
 		if e.state.FinalTurnsLeft == 0 {
 			e.finishGame()
@@ -510,17 +1041,143 @@ func (e *Engine) endTurn(playerID, actionType string) {
 		e.state.FinalTurnsLeft--
 	}
 
-	next := (idx + 1) % len(e.state.Players)
-	e.state.CurrentPlayerID = e.state.Players[next].ID
+	e.state.CurrentPlayerID = e.nextActivePlayer(idx)
 	e.state.Turn++
 }
 
+// applyResign takes a player out of the game. It is accepted at any time, on
+// turn or not. The player keeps their tokens and cards but can no longer win;
+// when a single active player is left the game ends.
+func (e *Engine) applyResign(playerID string, action Action, at time.Time, timeout bool) error {
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return fmt.Errorf("%w: player %s is not seated", ErrInvalidAction, playerID)
+	}
+	if e.state.Players[idx].Resigned {
+		return fmt.Errorf("%w: player %s already resigned", ErrInvalidAction, playerID)
+	}
+
+	before := e.capture(playerID)
+	onTurn := e.state.CurrentPlayerID == playerID
+	if onTurn {
+		// A seat above the token limit must not be left that way.
+		if pending := e.state.Pending; pending != nil && pending.Action == "discard_tokens" {
+			if err := e.resolveDiscard(playerID, e.timeoutAction(playerID).Payload.Colors); err != nil {
+				return err
+			}
+		}
+		e.state.Phase = PhaseAction
+		e.state.Pending = nil
+	}
+	// The final round loses the turn the player was still owed.
+	if e.state.FinalRound && e.hasFinalTurnLeft(playerID) {
+		e.state.FinalTurnsLeft--
+	}
+
+	p := &e.state.Players[idx]
+	p.Resigned = true
+	p.LastAction = "resign"
+	e.emit(Event{Type: EventPlayerResigned, PlayerID: playerID})
+
+	switch {
+	case e.activePlayers() <= 1 || e.state.FinalTurnsLeft < 0:
+		e.state.FinalTurnsLeft = max(e.state.FinalTurnsLeft, 0)
+		e.finishGame()
+	case onTurn:
+		e.state.CurrentPlayerID = e.nextActivePlayer(idx)
+		e.state.Turn++
+	}
+	e.record(before, action, at, timeout)
+	return nil
+}
+
+// hasFinalTurnLeft reports whether playerID is among the active players who
+// still move before the final round ends, counting the one on turn.
+func (e *Engine) hasFinalTurnLeft(playerID string) bool {
+	start := e.playerIndex(e.state.CurrentPlayerID)
+	seen := 0
+	for i := 0; i < len(e.state.Players) && seen <= e.state.FinalTurnsLeft; i++ {
+		p := e.state.Players[(start+i)%len(e.state.Players)]
+		if p.Resigned {
+			continue
+		}
+		if p.ID == playerID {
+			return true
+		}
+		seen++
+	}
+	return false
+}
+
+func (e *Engine) activePlayers() int {
+	n := 0
+	for _, p := range e.state.Players {
+		if !p.Resigned {
+			n++
+		}
+	}
+	return n
+}
+
+// nextActivePlayer returns the first player after seat idx who has not
+// resigned.
+func (e *Engine) nextActivePlayer(idx int) string {
+	for i := 1; i <= len(e.state.Players); i++ {
+		p := e.state.Players[(idx+i)%len(e.state.Players)]
+		if !p.Resigned {
+			return p.ID
+		}
+	}
+	return e.state.Players[idx].ID
+}
+
+// End finishes a game in progress where it stands, ranking the players
+// still in it as at a normal finish. It is not a player's action and is not
+// logged, so a Replay of the log stops short of it. Rooms call it once no
+// human is left playing.
+func (e *Engine) End() ([]Event, error) {
+	if e.fault != nil {
+		return nil, e.fault
+	}
+	if e.state.Status == StatusFinished {
+		return nil, nil
+	}
+	e.events = nil
+	defer func() { e.events = nil }()
+	// Like a resignation, the game must not end above the token limit.
+	if pending := e.state.Pending; pending != nil && pending.Action == "discard_tokens" {
+		if err := e.resolveDiscard(pending.PlayerID, e.timeoutAction(pending.PlayerID).Payload.Colors); err != nil {
+			return nil, err
+		}
+	}
+	e.state.Phase = PhaseAction
+	e.state.Pending = nil
+	e.finishGame()
+	if e.verify {
+		if err := CheckInvariants(*e.state, e.hidden()); err != nil {
+			e.fault = err
+			return nil, err
+		}
+	}
+	return e.events, nil
+}
+
 func (e *Engine) finishGame() {
 	e.state.Status = StatusFinished
 	e.state.WinnerIDs = computeWinners(e.state.Players)
+	e.emit(Event{Type: EventGameFinished, WinnerIDs: append([]string(nil), e.state.WinnerIDs...)})
 }
 
-func computeWinners(players []PlayerState) []string {
+// computeWinners ranks the players still in the game by points, breaking
+// ties with the fewest purchased cards.
+func computeWinners(all []PlayerState) []string {
+	players := make([]PlayerState, 0, len(all))
+	for _, p := range all {
+		if !p.Resigned {
+			players = append(players, p)
+		}
+	}
+
 	maxPoint := -1
 	for _, p := range players {
 		if p.Points > maxPoint {
@@ -1,6 +1,14 @@
 package game
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
 
 func TestNewGame(t *testing.T) {
 	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
@@ -29,7 +37,7 @@ func TestTakeTokensAndTurnSwitch(t *testing.T) {
 		t.Fatalf("new game failed: %v", err)
 	}
 
-	err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}})
+	_, err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}})
 	if err != nil {
 		t.Fatalf("apply failed: %v", err)
 	}
@@ -51,7 +59,7 @@ func TestTakeSingleTokenAllowed(t *testing.T) {
 	}
 
 	beforeWhite := engine.state.Bank.White
-	err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white"}}})
+	_, err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white"}}})
 	if err != nil {
 		t.Fatalf("single take failed: %v", err)
 	}
@@ -74,7 +82,7 @@ func TestTakeTwoDifferentTokensAllowed(t *testing.T) {
 
 	beforeWhite := engine.state.Bank.White
 	beforeBlue := engine.state.Bank.Blue
-	err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}})
+	_, err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}})
 	if err != nil {
 		t.Fatalf("take two different failed: %v", err)
 	}
@@ -95,7 +103,7 @@ func TestNotPlayerTurn(t *testing.T) {
 		t.Fatalf("new game failed: %v", err)
 	}
 
-	err = engine.Apply("p2", Action{Type: "pass"})
+	_, err = engine.Apply("p2", Action{Type: "pass"})
 	if err == nil {
 		t.Fatal("expected not turn error")
 	}
@@ -115,7 +123,7 @@ func TestDiscardTokens(t *testing.T) {
 	beforeWhite := engine.state.Bank.White
 	beforeBlue := engine.state.Bank.Blue
 
-	err = engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}})
+	_, err = engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}})
 	if err != nil {
 		t.Fatalf("discard failed: %v", err)
 	}
@@ -139,7 +147,7 @@ func TestDiscardTokensInsufficient(t *testing.T) {
 		t.Fatalf("new game failed: %v", err)
 	}
 
-	err = engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white"}}})
+	_, err = engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white"}}})
 	if err == nil {
 		t.Fatal("expected insufficient token discard error")
 	}
@@ -154,7 +162,7 @@ func TestAdjustTokensMixedTakeAndDiscard(t *testing.T) {
 
 	beforeBlue := engine.state.Bank.Blue
 	beforeWhite := engine.state.Bank.White
-	err = engine.Apply("p1", Action{Type: "adjust_tokens", Payload: ActionInput{
+	_, err = engine.Apply("p1", Action{Type: "adjust_tokens", Payload: ActionInput{
 		Adjust: map[string]int{
 			"blue":  1,
 			"white": -1,
@@ -173,3 +181,1047 @@ func TestAdjustTokensMixedTakeAndDiscard(t *testing.T) {
 		t.Fatalf("unexpected bank after adjust: %+v", s.Bank)
 	}
 }
+
+func TestNewWithSeedIsReproducible(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}}
+	first, err := NewWithSeed(seats, 42)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	second, err := NewWithSeed(seats, 42)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+
+	if first.Seed() != 42 || second.Seed() != 42 {
+		t.Fatalf("expected seed 42 to be recorded, got %d and %d", first.Seed(), second.Seed())
+	}
+	if !slices.Equal(first.deck1, second.deck1) || !slices.Equal(first.deck2, second.deck2) || !slices.Equal(first.deck3, second.deck3) {
+		t.Fatal("expected identical deck order for identical seeds")
+	}
+
+	a, b := first.Snapshot(), second.Snapshot()
+	if !slices.Equal(a.Tier1, b.Tier1) || !slices.Equal(a.Tier2, b.Tier2) || !slices.Equal(a.Tier3, b.Tier3) {
+		t.Fatal("expected identical tableau for identical seeds")
+	}
+	if !slices.Equal(a.Nobles, b.Nobles) {
+		t.Fatal("expected identical nobles for identical seeds")
+	}
+
+	other, err := NewWithSeed(seats, 43)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if slices.Equal(first.deck1, other.deck1) && slices.Equal(first.deck2, other.deck2) && slices.Equal(first.deck3, other.deck3) {
+		t.Fatal("expected different seeds to shuffle differently")
+	}
+}
+
+func TestReplayRebuildsIdenticalEngine(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}
+	engine, err := NewWithSeed(seats, 99)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+
+	steps := []struct {
+		player string
+		action Action
+	}{
+		{"p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}}},
+		{"p2", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"red", "red"}}}},
+		{"p1", Action{Type: "reserve_card", Payload: ActionInput{CardID: engine.state.Tier2[0].ID}}},
+		{"p2", Action{Type: "pass"}},
+	}
+	for _, step := range steps {
+		if _, err := engine.Apply(step.player, step.action); err != nil {
+			t.Fatalf("apply %s failed: %v", step.action.Type, err)
+		}
+	}
+
+	log := engine.Log()
+	if len(log) != len(steps) {
+		t.Fatalf("expected %d log entries, got %d", len(steps), len(log))
+	}
+	if log[1].Turn != 2 || log[1].PlayerID != "p2" || log[1].Delta.Tokens.Red != 2 || log[1].Delta.Bank.Red != -2 {
+		t.Fatalf("unexpected log entry: %+v", log[1])
+	}
+	if len(log[2].Delta.Reserved) != 1 || log[2].Delta.Tokens.Gold != 1 {
+		t.Fatalf("expected reserve delta with card and gold, got %+v", log[2].Delta)
+	}
+
+	blob, err := json.Marshal(log)
+	if err != nil {
+		t.Fatalf("marshal log failed: %v", err)
+	}
+	var decoded []LogEntry
+	if err := json.Unmarshal(blob, &decoded); err != nil {
+		t.Fatalf("unmarshal log failed: %v", err)
+	}
+
+	replayed, err := Replay(99, seats, decoded)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	if !reflect.DeepEqual(engine.Snapshot(), replayed.Snapshot()) {
+		t.Fatal("expected replayed state to match original")
+	}
+	if !slices.Equal(engine.deck1, replayed.deck1) || !slices.Equal(engine.deck2, replayed.deck2) || !slices.Equal(engine.deck3, replayed.deck3) {
+		t.Fatal("expected replayed decks to match original")
+	}
+	if len(replayed.Log()) != len(log) {
+		t.Fatalf("expected replayed log length %d, got %d", len(log), len(replayed.Log()))
+	}
+
+	if _, err := Replay(100, seats, decoded); !errors.Is(err, ErrReplayMismatch) && !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected replay with wrong seed to fail, got %v", err)
+	}
+}
+
+func TestLegalActionsAreAccepted(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}
+	setup := func() *Engine {
+		engine, err := NewWithSeed(seats, 5)
+		if err != nil {
+			t.Fatalf("new game failed: %v", err)
+		}
+		p := &engine.state.Players[0]
+		p.Tokens = TokenSet{White: 3, Blue: 2, Green: 2, Red: 1, Gold: 1}
+		engine.state.Bank.White -= 3
+		engine.state.Bank.Blue -= 2
+		engine.state.Bank.Green -= 2
+		engine.state.Bank.Red--
+		engine.state.Bank.Gold--
+		return engine
+	}
+
+	actions := setup().LegalActions("p1")
+	if len(actions) == 0 {
+		t.Fatal("expected legal actions for current player")
+	}
+	counts := map[string]int{}
+	for _, action := range actions {
+		counts[action.Type]++
+		if _, err := setup().Apply("p1", action); err != nil {
+			t.Fatalf("legal action %+v rejected: %v", action, err)
+		}
+	}
+	// Going over the limit is allowed and settled by a discard; only black
+	// still has the 4 tokens needed for a same-color pair.
+	if counts["take_tokens"] != 25+1 {
+		t.Fatalf("expected 26 takes at 9 tokens, got %d", counts["take_tokens"])
+	}
+	if counts["reserve_card"] != 15 || counts["pass"] != 1 {
+		t.Fatalf("unexpected action counts: %v", counts)
+	}
+
+	if got := setup().LegalActions("p2"); len(got) != 0 {
+		t.Fatalf("expected no legal actions off turn, got %d", len(got))
+	}
+}
+
+func TestLegalTakesFromFreshBank(t *testing.T) {
+	engine, err := NewWithSeed([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 5)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+
+	takes := 0
+	for _, action := range engine.LegalActions("p1") {
+		if action.Type == "take_tokens" {
+			takes++
+		}
+	}
+	// C(5,3) + C(5,2) + C(5,1) different-color takes plus 5 same-color pairs.
+	if takes != 10+10+5+5 {
+		t.Fatalf("expected 30 take actions, got %d", takes)
+	}
+}
+
+func TestReserveFromDeckIsBlind(t *testing.T) {
+	engine, err := NewWithSeed([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 11)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	top := engine.deck2[0]
+	beforeCount := engine.state.Deck2Count
+	tableau := append([]Card(nil), engine.state.Tier2...)
+
+	_, err = engine.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 2}})
+	if err != nil {
+		t.Fatalf("blind reserve failed: %v", err)
+	}
+
+	s := engine.Snapshot()
+	if s.Deck2Count != beforeCount-1 {
+		t.Fatalf("expected deck2 count %d, got %d", beforeCount-1, s.Deck2Count)
+	}
+	if !slices.Equal(s.Tier2, tableau) {
+		t.Fatal("expected tableau untouched by blind reserve")
+	}
+	reserved := s.Players[0].Reserved
+	if len(reserved) != 1 || reserved[0].ID != top.ID || !reserved[0].Blind {
+		t.Fatalf("expected blind reservation of %s, got %+v", top.ID, reserved)
+	}
+
+	own := s.RedactedFor("p1").Players[0].Reserved[0]
+	if own.ID != top.ID {
+		t.Fatalf("expected owner to see reserved card, got %+v", own)
+	}
+	hidden := s.RedactedFor("p2").Players[0].Reserved[0]
+	if hidden.ID != "" || hidden.Tier != 2 || !hidden.Blind || hidden.Cost != (TokenSet{}) {
+		t.Fatalf("expected card hidden from opponent, got %+v", hidden)
+	}
+	if s.Players[0].Reserved[0].ID != top.ID {
+		t.Fatal("expected redaction to leave the original state intact")
+	}
+
+	engine.deck3 = nil
+	engine.state.Deck3Count = 0
+	_, err = engine.Apply("p2", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 3}})
+	if !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected empty deck reserve to fail, got %v", err)
+	}
+}
+
+func TestTakeOverLimitAwaitsDiscard(t *testing.T) {
+	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	engine.state.Players[0].Tokens = TokenSet{White: 3, Blue: 3, Green: 3}
+	engine.state.Bank.White -= 3
+	engine.state.Bank.Blue -= 3
+	engine.state.Bank.Green -= 3
+
+	_, err = engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"red", "black"}}})
+	if err != nil {
+		t.Fatalf("take over limit failed: %v", err)
+	}
+
+	s := engine.Snapshot()
+	if s.CurrentPlayerID != "p1" || s.Phase != PhaseAwaitingDiscard {
+		t.Fatalf("expected p1 to await discard, got player %s phase %s", s.CurrentPlayerID, s.Phase)
+	}
+	if s.Pending == nil || s.Pending.DiscardCount != 1 || s.Pending.PlayerID != "p1" {
+		t.Fatalf("unexpected pending decision: %+v", s.Pending)
+	}
+
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected pass to be rejected while discard pending, got %v", err)
+	}
+	_, err = engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}})
+	if !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected discarding too many to fail, got %v", err)
+	}
+
+	legal := engine.LegalActions("p1")
+	if len(legal) != 5 {
+		t.Fatalf("expected one discard option per held color, got %d", len(legal))
+	}
+
+	if _, err := engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white"}}}); err != nil {
+		t.Fatalf("discard failed: %v", err)
+	}
+	s = engine.Snapshot()
+	if s.CurrentPlayerID != "p2" || s.Phase != PhaseAction || s.Pending != nil {
+		t.Fatalf("expected turn to end after discard, got player %s phase %s", s.CurrentPlayerID, s.Phase)
+	}
+	if s.Players[0].Tokens.Total() != 10 || s.Players[0].LastAction != "take_tokens" {
+		t.Fatalf("unexpected player after discard: %+v", s.Players[0])
+	}
+}
+
+func TestReserveAtLimitTakesGoldAndTimeoutDiscards(t *testing.T) {
+	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	engine.state.Players[0].Tokens = TokenSet{White: 4, Blue: 2, Green: 2, Red: 2}
+	engine.state.Bank.White -= 4
+	engine.state.Bank.Blue -= 2
+	engine.state.Bank.Green -= 2
+	engine.state.Bank.Red -= 2
+
+	_, err = engine.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{CardID: engine.state.Tier1[0].ID}})
+	if err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if s.Players[0].Tokens.Gold != 1 || s.Pending == nil || s.Pending.DiscardCount != 1 {
+		t.Fatalf("expected gold and a pending discard, got tokens %+v pending %+v", s.Players[0].Tokens, s.Pending)
+	}
+
+	if _, err := engine.ApplyTimeout("p1"); err != nil {
+		t.Fatalf("timeout failed: %v", err)
+	}
+	s = engine.Snapshot()
+	if s.CurrentPlayerID != "p2" || s.Pending != nil {
+		t.Fatalf("expected timeout to settle discard and end turn, got %+v", s.Pending)
+	}
+	if s.Players[0].Tokens.White != 3 || s.Players[0].Tokens.Gold != 1 {
+		t.Fatalf("expected timeout to give back from the largest stack, got %+v", s.Players[0].Tokens)
+	}
+}
+
+func TestNobleChoiceWhenSeveralQualify(t *testing.T) {
+	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	engine.state.Nobles = []Noble{
+		{ID: "n1", Points: 3, Requirement: TokenSet{White: 4, Blue: 4}},
+		{ID: "n2", Points: 3, Requirement: TokenSet{Red: 4, Black: 4}},
+		{ID: "n3", Points: 3, Requirement: TokenSet{White: 3, Blue: 3, Green: 3}},
+	}
+	engine.state.Players[0].Bonuses = TokenSet{White: 4, Blue: 4, Red: 4, Black: 4}
+
+	// Nobles are checked at the end of every turn, not just after buying.
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if s.Phase != PhaseChooseNoble || s.Pending == nil || !slices.Equal(s.Pending.NobleIDs, []string{"n1", "n2"}) {
+		t.Fatalf("expected choice between n1 and n2, got phase %s pending %+v", s.Phase, s.Pending)
+	}
+	if s.CurrentPlayerID != "p1" {
+		t.Fatalf("expected p1 to keep the turn, got %s", s.CurrentPlayerID)
+	}
+
+	_, err = engine.Apply("p1", Action{Type: "claim_noble", Payload: ActionInput{NobleID: "n3"}})
+	if !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected non-candidate claim to fail, got %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "claim_noble", Payload: ActionInput{NobleID: "n2"}}); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	s = engine.Snapshot()
+	if s.CurrentPlayerID != "p2" || s.Pending != nil || s.Phase != PhaseAction {
+		t.Fatalf("expected turn to end after claim, got player %s phase %s", s.CurrentPlayerID, s.Phase)
+	}
+	p1 := s.Players[0]
+	if len(p1.Nobles) != 1 || p1.Nobles[0].ID != "n2" || p1.Points != 3 || p1.LastAction != "pass" {
+		t.Fatalf("unexpected player after claim: %+v", p1)
+	}
+	if len(s.Nobles) != 2 {
+		t.Fatalf("expected 2 nobles left, got %d", len(s.Nobles))
+	}
+
+	// Only one noble per turn: n1 is still available for p1 next turn.
+	if _, err := engine.Apply("p2", Action{Type: "claim_noble", Payload: ActionInput{NobleID: "n1"}}); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected claim without pending choice to fail, got %v", err)
+	}
+	if _, err := engine.Apply("p2", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	s = engine.Snapshot()
+	if len(s.Players[0].Nobles) != 2 || s.CurrentPlayerID != "p2" {
+		t.Fatalf("expected single candidate to be claimed automatically, got %+v", s.Players[0].Nobles)
+	}
+}
+
+func TestNobleChoiceTimeoutPicksFirstCandidate(t *testing.T) {
+	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	engine.state.Nobles = []Noble{
+		{ID: "n7", Points: 3, Requirement: TokenSet{Blue: 4, Black: 4}},
+		{ID: "n5", Points: 3, Requirement: TokenSet{Blue: 4, Green: 4}},
+	}
+	engine.state.Players[0].Bonuses = TokenSet{Blue: 4, Green: 4, Black: 4}
+
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	if got := engine.LegalActions("p1"); len(got) != 2 || got[0].Type != "claim_noble" {
+		t.Fatalf("expected two claim options, got %+v", got)
+	}
+	if _, err := engine.ApplyTimeout("p1"); err != nil {
+		t.Fatalf("timeout failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if len(s.Players[0].Nobles) != 1 || s.Players[0].Nobles[0].ID != "n7" {
+		t.Fatalf("expected timeout to claim n7, got %+v", s.Players[0].Nobles)
+	}
+}
+
+func TestRuleSetVariant(t *testing.T) {
+	target, gold, extra := 5, 2, 0
+	rules, err := ResolveRules(PresetQuick, RuleOverrides{TargetScore: &target, GoldCount: &gold, ExtraNobles: &extra})
+	if err != nil {
+		t.Fatalf("resolve rules failed: %v", err)
+	}
+	if rules.Preset != PresetCustom {
+		t.Fatalf("expected overridden preset to be custom, got %s", rules.Preset)
+	}
+
+	engine, err := NewWithOptions([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, Options{Seed: 1, Rules: rules})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if s.Bank.Gold != 2 || len(s.Nobles) != 2 || s.Rules.TargetScore != 5 {
+		t.Fatalf("expected variant setup, got gold %d nobles %d rules %+v", s.Bank.Gold, len(s.Nobles), s.Rules)
+	}
+
+	engine.state.Players[0].Points = 5
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	if !engine.Snapshot().FinalRound {
+		t.Fatal("expected reaching the variant target score to start the final round")
+	}
+
+	if quick, _ := ResolveRules(PresetQuick, RuleOverrides{}); quick.Preset != PresetQuick || quick.TargetScore != 10 {
+		t.Fatalf("unexpected quick preset: %+v", quick)
+	}
+	zero := 0
+	if _, err := ResolveRules("", RuleOverrides{TargetScore: &zero}); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("expected invalid target score to fail, got %v", err)
+	}
+	if _, err := ResolveRules("blitz", RuleOverrides{}); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("expected unknown preset to fail, got %v", err)
+	}
+}
+
+func TestCustomCardSet(t *testing.T) {
+	var cards []Card
+	for i, c := range Cards() {
+		if i%3 == 0 {
+			cards = append(cards, c)
+		}
+	}
+	engine, err := NewWithOptions([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, Options{Seed: 4, Mode: ModeStrict, Cards: cards, CheckInvariants: true})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if got := len(s.Tier1) + len(s.Tier2) + len(s.Tier3) + s.Deck1Count + s.Deck2Count + s.Deck3Count; got != len(cards) {
+		t.Fatalf("expected %d cards dealt, got %d", len(cards), got)
+	}
+	if len(s.CardSet) != len(cards) {
+		t.Fatalf("expected the card set in the state, got %d cards", len(s.CardSet))
+	}
+	if _, err := engine.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 2}}); err != nil {
+		t.Fatalf("blind reserve failed: %v", err)
+	}
+	if _, err := Determinize(engine.Snapshot().RedactedFor("p2"), "p2", rand.New(rand.NewSource(1))); err != nil {
+		t.Fatalf("determinize failed: %v", err)
+	}
+	data, err := engine.Marshal()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if _, err := Restore(data); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	bad := append([]Card(nil), cards...)
+	bad[1].ID = bad[0].ID
+	if _, err := NewWithOptions([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, Options{Cards: bad}); !errors.Is(err, ErrInvalidCardSet) {
+		t.Fatalf("expected duplicate card ids to fail, got %v", err)
+	}
+	bad = append([]Card(nil), cards...)
+	bad[0].Cost.Gold = 1
+	if err := ValidateCards(bad); !errors.Is(err, ErrInvalidCardSet) {
+		t.Fatalf("expected gold cost to fail, got %v", err)
+	}
+}
+
+func TestStrictModeRejectsSandboxMoves(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}
+	engine, err := NewWithOptions(seats, Options{Seed: 3, Mode: ModeStrict})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if engine.Snapshot().Mode != ModeStrict {
+		t.Fatalf("expected strict mode in state, got %s", engine.Snapshot().Mode)
+	}
+
+	_, err = engine.Apply("p1", Action{Type: "adjust_tokens", Payload: ActionInput{Adjust: map[string]int{"blue": 1}}})
+	if !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected adjust_tokens to be rejected, got %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected pass to be rejected while moves exist, got %v", err)
+	}
+	engine.state.Players[0].Tokens.Blue = 1
+	engine.state.Bank.Blue--
+	discard := Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"blue"}}}
+	if _, err := engine.Apply("p1", discard); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected a voluntary discard to be rejected, got %v", err)
+	}
+	engine.state.Players[0].Tokens.Blue = 0
+	engine.state.Bank.Blue++
+	for _, action := range engine.LegalActions("p1") {
+		if action.Type == "pass" {
+			t.Fatal("expected pass to be omitted from strict legal actions")
+		}
+	}
+
+	if _, err := engine.ApplyTimeout("p1"); err != nil {
+		t.Fatalf("expected timeout pass to be allowed, got %v", err)
+	}
+	log := engine.Log()
+	if len(log) != 1 || !log[0].Timeout {
+		t.Fatalf("expected timeout pass in log, got %+v", log)
+	}
+	if _, err := ReplayWithOptions(Options{Seed: 3, Mode: ModeStrict}, seats, log); err != nil {
+		t.Fatalf("expected strict replay with timeout to succeed, got %v", err)
+	}
+
+	// With nothing else possible a strict pass is accepted.
+	engine.state.Bank = TokenSet{}
+	engine.state.Tier1, engine.state.Tier2, engine.state.Tier3 = nil, nil, nil
+	engine.state.Deck1Count, engine.state.Deck2Count, engine.state.Deck3Count = 0, 0, 0
+	if _, err := engine.Apply("p2", Action{Type: "pass"}); err != nil {
+		t.Fatalf("expected forced pass to be accepted, got %v", err)
+	}
+
+	if _, err := NewWithOptions(seats, Options{Mode: "chaos"}); !errors.Is(err, ErrInvalidMode) {
+		t.Fatalf("expected unknown mode to fail, got %v", err)
+	}
+}
+
+func TestBuyCardWithChosenPayment(t *testing.T) {
+	setup := func() (*Engine, Card) {
+		engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+		if err != nil {
+			t.Fatalf("new game failed: %v", err)
+		}
+		card := Card{ID: "test_card", Tier: 1, Bonus: GemRed, Points: 1, Cost: TokenSet{White: 2, Blue: 1}}
+		engine.state.Tier1[0] = card
+		p := &engine.state.Players[0]
+		p.Tokens = TokenSet{White: 2, Blue: 1, Gold: 2}
+		p.Bonuses = TokenSet{Blue: 0}
+		return engine, card
+	}
+
+	engine, card := setup()
+	pay := TokenSet{White: 1, Gold: 2}
+	_, err := engine.Apply("p1", Action{Type: "buy_card", Payload: ActionInput{CardID: card.ID, Payment: &pay}})
+	if err != nil {
+		t.Fatalf("buy with chosen payment failed: %v", err)
+	}
+	p1 := engine.Snapshot().Players[0]
+	if p1.Tokens != (TokenSet{White: 1, Blue: 1}) || p1.Bonuses.Red != 1 {
+		t.Fatalf("expected to keep one white and the blue, got %+v", p1.Tokens)
+	}
+
+	invalid := []TokenSet{
+		{White: 2, Blue: 1, Gold: 1}, // gold on top of a full payment
+		{White: 1, Blue: 1},          // short by one
+		{White: 3},                   // overpays white and lacks tokens
+		{Gold: 3},                    // more gold than held
+	}
+	for _, pay := range invalid {
+		engine, card := setup()
+		_, err := engine.Apply("p1", Action{Type: "buy_card", Payload: ActionInput{CardID: card.ID, Payment: &pay}})
+		if !errors.Is(err, ErrInvalidAction) {
+			t.Fatalf("expected payment %+v to be rejected, got %v", pay, err)
+		}
+		if engine.Snapshot().Players[0].Tokens != (TokenSet{White: 2, Blue: 1, Gold: 2}) || len(engine.state.Tier1) != 4 {
+			t.Fatalf("expected rejected payment %+v to leave state untouched", pay)
+		}
+	}
+
+	engine, card = setup()
+	if _, err := engine.Apply("p1", Action{Type: "buy_card", Payload: ActionInput{CardID: card.ID}}); err != nil {
+		t.Fatalf("automatic payment failed: %v", err)
+	}
+	if got := engine.Snapshot().Players[0].Tokens; got != (TokenSet{Gold: 2}) {
+		t.Fatalf("expected automatic payment to spend colored tokens first, got %+v", got)
+	}
+}
+
+func TestPreviewPurchase(t *testing.T) {
+	engine, err := New([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	card := Card{ID: "test_card", Tier: 1, Bonus: GemRed, Cost: TokenSet{White: 3, Blue: 2, Red: 1}}
+	engine.state.Tier1[0] = card
+	p := &engine.state.Players[1]
+	p.Tokens = TokenSet{White: 1, Blue: 2, Gold: 1}
+	p.Bonuses = TokenSet{Red: 1}
+	before := engine.Snapshot()
+
+	preview, err := engine.PreviewPurchase("p2", card.ID, "")
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if preview.Affordable || preview.Source != "tableau" {
+		t.Fatalf("expected unaffordable tableau preview, got %+v", preview)
+	}
+	if preview.Shortfall != (TokenSet{White: 2, Gold: 1}) {
+		t.Fatalf("expected 2 white short with 1 gold missing, got %+v", preview.Shortfall)
+	}
+
+	p.Tokens.Gold = 2
+	preview, err = engine.PreviewPurchase("p2", card.ID, "tableau")
+	if err != nil {
+		t.Fatalf("preview failed: %v", err)
+	}
+	if !preview.Affordable || preview.Payment != (TokenSet{White: 1, Blue: 2, Gold: 2}) || preview.Shortfall.Gold != 0 {
+		t.Fatalf("unexpected affordable preview: %+v", preview)
+	}
+
+	if _, err := engine.PreviewPurchase("p2", card.ID, "reserved"); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected missing reserved card to fail, got %v", err)
+	}
+	if after := engine.Snapshot(); !slices.Equal(after.Tier1, before.Tier1) || after.CurrentPlayerID != before.CurrentPlayerID {
+		t.Fatal("expected preview to leave the game untouched")
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	engine, err := NewWithOptions([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, Options{Seed: 8, CheckInvariants: true})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if err := CheckInvariants(engine.Snapshot(), engine.hidden()); err != nil {
+		t.Fatalf("expected fresh game to be consistent: %v", err)
+	}
+
+	moves := []struct {
+		player string
+		action Action
+	}{
+		{"p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "black"}}}},
+		{"p2", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 3}}},
+		{"p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"red", "green", "black"}}}},
+	}
+	for _, m := range moves {
+		if _, err := engine.Apply(m.player, m.action); err != nil {
+			t.Fatalf("apply %s failed: %v", m.action.Type, err)
+		}
+	}
+
+	state, hidden := engine.Snapshot(), engine.hidden()
+	state.Bank.White++
+	state.Players[1].Reserved = append(state.Players[1].Reserved, state.Tier1[0])
+	err = CheckInvariants(state, hidden)
+	if !errors.Is(err, ErrInvariantViolation) {
+		t.Fatalf("expected violation, got %v", err)
+	}
+	for _, want := range []string{"white tokens total", "in both tier 1 and reserve of p2"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected %q in %v", want, err)
+		}
+	}
+
+	// A corrupted engine reports the violation and refuses further moves.
+	engine.state.Bank.Gold--
+	if _, err := engine.Apply("p2", Action{Type: "pass"}); !errors.Is(err, ErrInvariantViolation) {
+		t.Fatalf("expected violation after apply, got %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); !errors.Is(err, ErrInvariantViolation) {
+		t.Fatalf("expected corrupted engine to refuse moves, got %v", err)
+	}
+}
+
+func TestTokenMovesKeepSupplyUnderInvariantCheck(t *testing.T) {
+	engine, err := NewWithOptions([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, Options{Seed: 9, CheckInvariants: true})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	giveTokens(engine, "p1", TokenSet{White: 2, Blue: 1})
+	giveTokens(engine, "p2", TokenSet{White: 1})
+
+	if _, err := engine.Apply("p1", Action{Type: "discard_tokens", Payload: ActionInput{Colors: []string{"white", "blue"}}}); err != nil {
+		t.Fatalf("discard failed: %v", err)
+	}
+	if _, err := engine.Apply("p2", Action{Type: "adjust_tokens", Payload: ActionInput{Adjust: map[string]int{"blue": 1, "white": -1}}}); err != nil {
+		t.Fatalf("adjust failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if s.Players[0].Tokens != (TokenSet{White: 1}) || s.Players[1].Tokens != (TokenSet{Blue: 1}) {
+		t.Fatalf("unexpected tokens: %+v %+v", s.Players[0].Tokens, s.Players[1].Tokens)
+	}
+	if err := CheckInvariants(s, engine.hidden()); err != nil {
+		t.Fatalf("token moves broke conservation: %v", err)
+	}
+}
+
+// giveTokens moves tokens from the bank to a player, so a position set up by
+// hand still passes CheckInvariants.
+func giveTokens(engine *Engine, playerID string, tokens TokenSet) {
+	p := &engine.state.Players[engine.playerIndex(playerID)]
+	for _, color := range append(append([]string(nil), ColoredGems...), GemGold) {
+		n := tokens.Get(color)
+		engine.state.Bank.Sub(color, n)
+		p.Tokens.Add(color, n)
+	}
+}
+
+func TestMarshalRestoreRoundTrip(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}
+	engine, err := NewWithSeed(seats, 7)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}}); err != nil {
+		t.Fatalf("take failed: %v", err)
+	}
+	if _, err := engine.Apply("p2", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 1}}); err != nil {
+		t.Fatalf("blind reserve failed: %v", err)
+	}
+
+	data, err := engine.MarshalBinary()
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	var restored Engine
+	if err := restored.UnmarshalBinary(data); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(restored.Snapshot(), engine.Snapshot()) {
+		t.Fatalf("restored state differs")
+	}
+	if !reflect.DeepEqual(restored.hidden(), engine.hidden()) {
+		t.Fatalf("restored decks differ")
+	}
+	if !reflect.DeepEqual(restored.Log(), engine.Log()) || restored.Seed() != engine.Seed() {
+		t.Fatalf("restored log or seed differs")
+	}
+
+	// Both engines must keep drawing the same cards.
+	next := Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 2}}
+	if _, err := engine.Apply("p1", next); err != nil {
+		t.Fatalf("apply on original failed: %v", err)
+	}
+	if _, err := restored.Apply("p1", next); err != nil {
+		t.Fatalf("apply on restored failed: %v", err)
+	}
+	if !reflect.DeepEqual(restored.Snapshot(), engine.Snapshot()) {
+		t.Fatalf("engines diverged after restore")
+	}
+
+	if _, err := Restore([]byte(`{"version":99}`)); !errors.Is(err, ErrInvalidSerialization) {
+		t.Fatalf("expected ErrInvalidSerialization for unknown version, got %v", err)
+	}
+	tampered := strings.Replace(string(data), `"gold":`, `"gold":9`, 1)
+	if _, err := Restore([]byte(tampered)); !errors.Is(err, ErrInvariantViolation) {
+		t.Fatalf("expected invariant violation for tampered data, got %v", err)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	engine, err := NewWithSeed([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 11)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}}); err != nil {
+		t.Fatalf("take failed: %v", err)
+	}
+
+	clone := engine.Clone()
+	if _, err := clone.Apply("p2", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 1}}); err != nil {
+		t.Fatalf("apply on clone failed: %v", err)
+	}
+	if _, err := engine.Apply("p2", Action{Type: "pass"}); err != nil {
+		t.Fatalf("apply on original failed: %v", err)
+	}
+
+	original := engine.Snapshot()
+	if len(original.Players[1].Reserved) != 0 || original.Deck1Count != clone.Snapshot().Deck1Count+1 {
+		t.Fatalf("clone move leaked into original")
+	}
+	if len(engine.Log()) != 2 || len(clone.Log()) != 2 || engine.Log()[1].Action.Type != "pass" || clone.Log()[1].Action.Type != "reserve_card" {
+		t.Fatalf("logs not independent")
+	}
+	if err := CheckInvariants(clone.Snapshot(), clone.hidden()); err != nil {
+		t.Fatalf("clone violates invariants: %v", err)
+	}
+}
+
+func TestDeterminizeFromRedactedView(t *testing.T) {
+	engine, err := NewWithSeed([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 12)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 2}}); err != nil {
+		t.Fatalf("p1 blind reserve failed: %v", err)
+	}
+	if _, err := engine.Apply("p2", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 3}}); err != nil {
+		t.Fatalf("p2 blind reserve failed: %v", err)
+	}
+
+	view := engine.Snapshot().RedactedFor("p1")
+	sample, err := Determinize(view, "p1", rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("determinize failed: %v", err)
+	}
+	state := sample.Snapshot()
+	if err := CheckInvariants(state, sample.hidden()); err != nil {
+		t.Fatalf("determinized engine violates invariants: %v", err)
+	}
+	if state.Players[0].Reserved[0].ID != view.Players[0].Reserved[0].ID {
+		t.Fatalf("viewer's own blind card changed")
+	}
+	guess := state.Players[1].Reserved[0]
+	if guess.ID == "" || guess.Tier != 3 || !guess.Blind {
+		t.Fatalf("expected a sampled tier 3 blind card, got %+v", guess)
+	}
+	if !reflect.DeepEqual(state.Tier1, view.Tier1) || state.Deck3Count != view.Deck3Count {
+		t.Fatalf("visible cards changed")
+	}
+
+	// Resampling a clone redraws the opponent's blind card as well as the
+	// decks, and leaves the sample it was cloned from alone.
+	guesses := map[string]bool{}
+	rng := rand.New(rand.NewSource(2))
+	for i := 0; i < 20; i++ {
+		clone := sample.Clone()
+		clone.Resample("p1", rng)
+		resampled := clone.Snapshot()
+		if err := CheckInvariants(resampled, clone.hidden()); err != nil {
+			t.Fatalf("resampled engine violates invariants: %v", err)
+		}
+		if resampled.Players[0].Reserved[0] != state.Players[0].Reserved[0] || !reflect.DeepEqual(resampled.Tier3, state.Tier3) {
+			t.Fatal("resampling changed cards the viewer can see")
+		}
+		if c := resampled.Players[1].Reserved[0]; c.Tier != 3 || !c.Blind {
+			t.Fatalf("expected a tier 3 blind card, got %+v", c)
+		}
+		guesses[resampled.Players[1].Reserved[0].ID] = true
+	}
+	if len(guesses) < 2 || sample.Snapshot().Players[1].Reserved[0] != guess {
+		t.Fatalf("expected varied guesses without touching the sample, got %d", len(guesses))
+	}
+
+	if _, err := sample.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 1}}); err != nil {
+		t.Fatalf("determinized engine cannot play on: %v", err)
+	}
+}
+
+// BenchmarkClone measures what the hard bot does every search iteration:
+// clone a determinized engine and redraw the cards the bot cannot see.
+func BenchmarkClone(b *testing.B) {
+	engine := benchmarkEngine(b)
+	rng := rand.New(rand.NewSource(1))
+	sample, err := Determinize(engine.Snapshot().RedactedFor("p1"), "p1", rng)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sample.Clone().Resample("p1", rng)
+	}
+}
+
+func BenchmarkMarshalRestore(b *testing.B) {
+	engine := benchmarkEngine(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		data, err := engine.Marshal()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := Restore(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkEngine is a four-player game a few rounds in.
+func benchmarkEngine(b *testing.B) *Engine {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}, {ID: "p4", Name: "D"}}
+	engine, err := NewWithSeed(seats, 21)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		current := engine.CurrentPlayerID()
+		if _, err := engine.Apply(current, engine.LegalActions(current)[0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return engine
+}
+
+func TestApplyReturnsEvents(t *testing.T) {
+	engine, err := NewWithSeed([]Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}}, 31)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+
+	events, err := engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"red", "red"}}})
+	if err != nil {
+		t.Fatalf("take failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventTokensTaken || events[0].Tokens.Red != 2 {
+		t.Fatalf("unexpected take events: %+v", events)
+	}
+
+	target := engine.state.Tier1[0]
+	events, err = engine.Apply("p2", Action{Type: "reserve_card", Payload: ActionInput{CardID: target.ID}})
+	if err != nil {
+		t.Fatalf("reserve failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Type != EventCardReserved || events[0].Card.ID != target.ID || events[0].Tokens.Gold != 1 {
+		t.Fatalf("unexpected reserve events: %+v", events)
+	}
+	if events[1].Type != EventCardRevealed || events[1].Card.ID != engine.state.Tier1[3].ID {
+		t.Fatalf("expected the refill to be revealed, got %+v", events[1])
+	}
+
+	events, err = engine.Apply("p1", Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: 2}})
+	if err != nil {
+		t.Fatalf("blind reserve failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Card.ID == "" || !events[0].Card.Blind {
+		t.Fatalf("unexpected blind reserve events: %+v", events)
+	}
+	if redacted := RedactEvents(events, "p2"); redacted[0].Card.ID != "" || redacted[0].Card.Tier != 2 || events[0].Card.ID == "" {
+		t.Fatalf("expected the blind card hidden from p2 only, got %+v", redacted[0].Card)
+	}
+
+	// p2 buys its reserved card for the winning points.
+	p2 := &engine.state.Players[1]
+	p2.Points = 15 - target.Points
+	engine.state.Bank.Sub(GemGold, 4)
+	p2.Tokens.Gold += 4
+	p2.Bonuses = TokenSet{White: 9, Blue: 9, Green: 9, Red: 9, Black: 9}
+	engine.state.Nobles = nil
+	events, err = engine.Apply("p2", Action{Type: "buy_card", Payload: ActionInput{CardID: target.ID, Source: "reserved"}})
+	if err != nil {
+		t.Fatalf("buy failed: %v", err)
+	}
+	types := make([]string, len(events))
+	for i, ev := range events {
+		types[i] = ev.Type
+	}
+	if !slices.Equal(types, []string{EventCardBought, EventFinalRoundStarted}) {
+		t.Fatalf("unexpected buy events: %v", types)
+	}
+
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	events, err = engine.Apply("p2", Action{Type: "pass"})
+	if err != nil {
+		t.Fatalf("pass failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventGameFinished || !slices.Equal(events[0].WinnerIDs, []string{"p2"}) {
+		t.Fatalf("expected game_finished for p2, got %+v", events)
+	}
+
+	if _, err := engine.Apply("p1", Action{Type: "pass"}); err == nil {
+		t.Fatal("expected finished game to reject actions")
+	}
+}
+
+func TestResign(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}, {ID: "p4", Name: "D"}}
+	opts := Options{Seed: 8, CheckInvariants: true}
+	engine, err := NewWithOptions(seats, opts)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	pass := Action{Type: "pass"}
+	resign := Action{Type: "resign"}
+
+	// Resigning does not need the turn.
+	events, err := engine.Apply("p3", resign)
+	if err != nil {
+		t.Fatalf("resign failed: %v", err)
+	}
+	if len(events) != 1 || events[0].Type != EventPlayerResigned || events[0].PlayerID != "p3" {
+		t.Fatalf("unexpected resign events: %+v", events)
+	}
+	if _, err := engine.Apply("p3", resign); !errors.Is(err, ErrInvalidAction) {
+		t.Fatalf("expected a second resign to fail, got %v", err)
+	}
+	for _, id := range []string{"p1", "p2"} {
+		if _, err := engine.Apply(id, pass); err != nil {
+			t.Fatalf("%s pass failed: %v", id, err)
+		}
+	}
+	if got := engine.CurrentPlayerID(); got != "p4" {
+		t.Fatalf("expected the resigned seat to be skipped, got %s on turn", got)
+	}
+
+	// p4 starts the final round; p2 leaves before its last turn.
+	engine.state.Players[3].Points = 15
+	if _, err := engine.Apply("p4", pass); err != nil {
+		t.Fatalf("p4 pass failed: %v", err)
+	}
+	if s := engine.Snapshot(); !s.FinalRound || s.FinalTurnsLeft != 2 {
+		t.Fatalf("expected two final turns left among three players, got %d", s.FinalTurnsLeft)
+	}
+	if _, err := engine.Apply("p2", resign); err != nil {
+		t.Fatalf("p2 resign failed: %v", err)
+	}
+	if s := engine.Snapshot(); s.FinalTurnsLeft != 1 {
+		t.Fatalf("expected the resigned player's final turn to be dropped, got %d", s.FinalTurnsLeft)
+	}
+	if _, err := engine.Apply("p1", pass); err != nil {
+		t.Fatalf("p1 pass failed: %v", err)
+	}
+	if _, err := engine.Apply("p4", pass); err != nil {
+		t.Fatalf("p4 pass failed: %v", err)
+	}
+	s := engine.Snapshot()
+	if s.Status != StatusFinished || !slices.Equal(s.WinnerIDs, []string{"p4"}) || !s.Players[1].Resigned || !s.Players[2].Resigned {
+		t.Fatalf("unexpected final state: status %s winners %v", s.Status, s.WinnerIDs)
+	}
+
+	if _, err := ReplayWithOptions(opts, seats, engine.Log()); err != nil {
+		t.Fatalf("replay with resignations failed: %v", err)
+	}
+
+	// The last player standing wins, whatever the score.
+	duel, err := NewWithSeed(seats[:2], 3)
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	duel.state.Players[0].Points = 9
+	events, err = duel.Apply("p1", resign)
+	if err != nil {
+		t.Fatalf("resign failed: %v", err)
+	}
+	if len(events) != 2 || events[1].Type != EventGameFinished || !slices.Equal(events[1].WinnerIDs, []string{"p2"}) {
+		t.Fatalf("expected p2 to win by resignation, got %+v", events)
+	}
+}
+
+func TestEnd(t *testing.T) {
+	seats := []Seat{{ID: "p1", Name: "A"}, {ID: "p2", Name: "B"}, {ID: "p3", Name: "C"}}
+	engine, err := NewWithOptions(seats, Options{Seed: 4, CheckInvariants: true})
+	if err != nil {
+		t.Fatalf("new game failed: %v", err)
+	}
+	if _, err := engine.Apply("p1", Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{"white", "blue", "green"}}}); err != nil {
+		t.Fatalf("take failed: %v", err)
+	}
+	if _, err := engine.Apply("p3", Action{Type: "resign"}); err != nil {
+		t.Fatalf("resign failed: %v", err)
+	}
+	logged := len(engine.Log())
+
+	events, err := engine.End()
+	if err != nil {
+		t.Fatalf("end failed: %v", err)
+	}
+	state := engine.Snapshot()
+	if state.Status != StatusFinished || len(events) != 1 || events[0].Type != EventGameFinished {
+		t.Fatalf("expected the game to finish, got %s with %+v", state.Status, events)
+	}
+	// Nobody has points, so the tie goes to both players still in.
+	if !slices.Equal(state.WinnerIDs, []string{"p1", "p2"}) {
+		t.Fatalf("expected p1 and p2 to share the win, got %v", state.WinnerIDs)
+	}
+	if len(engine.Log()) != logged {
+		t.Fatal("expected End to stay out of the log")
+	}
+	if events, err := engine.End(); err != nil || events != nil {
+		t.Fatalf("expected a second End to do nothing, got %+v %v", events, err)
+	}
+}
@@ -0,0 +1,242 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvariantViolation = errors.New("game invariant violated")
+
+// Hidden is the part of a game no player can see: the undealt decks, top
+// card first.
+type Hidden struct {
+	Deck1 []Card `json:"deck1"`
+	Deck2 []Card `json:"deck2"`
+	Deck3 []Card `json:"deck3"`
+}
+
+func (e *Engine) hidden() Hidden {
+	return Hidden{
+		Deck1: append([]Card(nil), e.deck1...),
+		Deck2: append([]Card(nil), e.deck2...),
+		Deck3: append([]Card(nil), e.deck3...),
+	}
+}
+
+// CheckInvariants verifies that a full, unredacted state is consistent:
+// tokens and cards are conserved, players stay within the rule limits and the
+// turn bookkeeping agrees with itself. Every problem found is listed in the
+// returned error, which wraps ErrInvariantViolation.
+func CheckInvariants(state State, hidden Hidden) error {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if err := state.Rules.Validate(); err != nil {
+		fail("rules: %v", err)
+		return invariantError(problems)
+	}
+	if len(state.Players) < 2 || len(state.Players) > 4 {
+		fail("player count %d out of range", len(state.Players))
+		return invariantError(problems)
+	}
+
+	checkTokens(state, fail)
+	checkCards(state, hidden, fail)
+	checkPlayers(state, fail)
+	checkTurn(state, fail)
+	return invariantError(problems)
+}
+
+func invariantError(problems []string) error {
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvariantViolation, strings.Join(problems, "; "))
+}
+
+func checkTokens(state State, fail func(string, ...any)) {
+	colors := append(append([]string(nil), ColoredGems...), GemGold)
+	for _, color := range colors {
+		supply := state.Rules.GoldCount
+		if color != GemGold {
+			supply = state.Rules.bankTokens(len(state.Players))
+		}
+		total := state.Bank.Get(color)
+		if state.Bank.Get(color) < 0 {
+			fail("bank has negative %s", color)
+		}
+		for _, p := range state.Players {
+			if p.Tokens.Get(color) < 0 {
+				fail("player %s has negative %s", p.ID, color)
+			}
+			total += p.Tokens.Get(color)
+		}
+		if total != supply {
+			fail("%s tokens total %d, want %d", color, total, supply)
+		}
+	}
+}
+
+func checkCards(state State, hidden Hidden, fail func(string, ...any)) {
+	tiers := map[string]int{}
+	for _, c := range state.cards() {
+		tiers[c.ID] = c.Tier
+	}
+	seen := map[string]string{}
+	see := func(id, zone string, tier int) {
+		wantTier, ok := tiers[id]
+		switch {
+		case !ok:
+			fail("unknown card %q in %s", id, zone)
+			return
+		case tier != 0 && wantTier != tier:
+			fail("card %s of tier %d found in %s", id, wantTier, zone)
+		}
+		if prev, dup := seen[id]; dup {
+			fail("card %s in both %s and %s", id, prev, zone)
+			return
+		}
+		seen[id] = zone
+	}
+
+	for i, pile := range []struct {
+		tableau []Card
+		deck    []Card
+		count   int
+	}{
+		{state.Tier1, hidden.Deck1, state.Deck1Count},
+		{state.Tier2, hidden.Deck2, state.Deck2Count},
+		{state.Tier3, hidden.Deck3, state.Deck3Count},
+	} {
+		tier := i + 1
+		if len(pile.tableau) > 4 {
+			fail("tier %d shows %d cards", tier, len(pile.tableau))
+		}
+		if len(pile.deck) > 0 && len(pile.tableau) < 4 {
+			fail("tier %d shows %d cards while its deck is not empty", tier, len(pile.tableau))
+		}
+		if pile.count != len(pile.deck) {
+			fail("deck %d count %d, want %d", tier, pile.count, len(pile.deck))
+		}
+		for _, c := range pile.tableau {
+			see(c.ID, fmt.Sprintf("tier %d", tier), tier)
+		}
+		for _, c := range pile.deck {
+			see(c.ID, fmt.Sprintf("deck %d", tier), tier)
+		}
+	}
+	for _, p := range state.Players {
+		for _, c := range p.Reserved {
+			see(c.ID, "reserve of "+p.ID, 0)
+		}
+		for _, id := range p.PurchasedIDs {
+			see(id, "purchases of "+p.ID, 0)
+		}
+	}
+	if len(seen) != len(tiers) {
+		fail("%d cards accounted for, want %d", len(seen), len(tiers))
+	}
+
+	nobles := map[string]bool{}
+	for _, n := range noblesDataset() {
+		nobles[n.ID] = true
+	}
+	claimed := map[string]bool{}
+	claim := func(id string) {
+		if !nobles[id] {
+			fail("unknown noble %q", id)
+		}
+		if claimed[id] {
+			fail("noble %s appears twice", id)
+		}
+		claimed[id] = true
+	}
+	for _, n := range state.Nobles {
+		claim(n.ID)
+	}
+	for _, p := range state.Players {
+		for _, n := range p.Nobles {
+			claim(n.ID)
+		}
+	}
+	if want := len(state.Players) + state.Rules.ExtraNobles; len(claimed) != want {
+		fail("%d nobles accounted for, want %d", len(claimed), want)
+	}
+}
+
+func checkPlayers(state State, fail func(string, ...any)) {
+	ids := map[string]bool{}
+	for _, p := range state.Players {
+		if ids[p.ID] {
+			fail("duplicate player %s", p.ID)
+		}
+		ids[p.ID] = true
+
+		overLimit := p.Tokens.Total() > state.Rules.TokenLimit
+		discarding := state.Pending != nil && state.Pending.PlayerID == p.ID && state.Pending.Action == "discard_tokens"
+		if overLimit && !discarding {
+			fail("player %s holds %d tokens, limit %d", p.ID, p.Tokens.Total(), state.Rules.TokenLimit)
+		}
+		if discarding && p.Tokens.Total()-state.Pending.DiscardCount != state.Rules.TokenLimit {
+			fail("player %s must discard %d but holds %d", p.ID, state.Pending.DiscardCount, p.Tokens.Total())
+		}
+		if len(p.Reserved) > state.Rules.ReserveLimit {
+			fail("player %s reserved %d cards, limit %d", p.ID, len(p.Reserved), state.Rules.ReserveLimit)
+		}
+		if p.PurchasedCount != len(p.PurchasedIDs) || p.PurchasedCount != p.Bonuses.Total() {
+			fail("player %s purchased %d cards but has %d ids and %d bonuses", p.ID, p.PurchasedCount, len(p.PurchasedIDs), p.Bonuses.Total())
+		}
+	}
+}
+
+func checkTurn(state State, fail func(string, ...any)) {
+	if state.Turn < 1 {
+		fail("turn %d is not positive", state.Turn)
+	}
+	current := false
+	for _, p := range state.Players {
+		if p.ID == state.CurrentPlayerID {
+			current = true
+		}
+	}
+	if !current {
+		fail("current player %q is not seated", state.CurrentPlayerID)
+	}
+
+	switch state.Status {
+	case StatusPlaying:
+		if len(state.WinnerIDs) > 0 {
+			fail("winners set while playing")
+		}
+		for _, p := range state.Players {
+			if p.ID == state.CurrentPlayerID && p.Resigned {
+				fail("resigned player %s is on turn", p.ID)
+			}
+		}
+	case StatusFinished:
+		if len(state.WinnerIDs) == 0 {
+			fail("finished without winners")
+		}
+	default:
+		fail("unknown status %q", state.Status)
+	}
+
+	if state.FinalTurnsLeft < 0 || state.FinalTurnsLeft >= len(state.Players) {
+		fail("finalTurnsLeft %d out of range", state.FinalTurnsLeft)
+	}
+	if !state.FinalRound && state.FinalTurnsLeft != 0 {
+		fail("finalTurnsLeft %d outside the final round", state.FinalTurnsLeft)
+	}
+
+	switch {
+	case state.Phase == PhaseAction && state.Pending != nil:
+		fail("pending decision in action phase")
+	case state.Phase != PhaseAction && state.Pending == nil:
+		fail("phase %s without a pending decision", state.Phase)
+	case state.Pending != nil && state.Pending.PlayerID != state.CurrentPlayerID:
+		fail("pending decision belongs to %s, not the current player", state.Pending.PlayerID)
+	}
+}
@@ -0,0 +1,146 @@
+package game
+
+// LegalActions lists every action Apply would accept from playerID in the
+// current state: each token combination, each reservable face-up card and
+// deck top, each affordable tableau or reserved card, and pass (in strict
+// games only when nothing else is possible). While a discard or noble choice
+// is pending only the ways to settle it are listed. The free-form sandbox
+// moves (adjust_tokens and voluntary discard_tokens) take arbitrary
+// quantities and are not enumerated. Players who are not on turn get an
+// empty list.
+func (e *Engine) LegalActions(playerID string) []Action {
+	out := make([]Action, 0)
+	if e.state.Status == StatusFinished || e.state.CurrentPlayerID != playerID {
+		return out
+	}
+	idx := e.playerIndex(playerID)
+	if idx == -1 {
+		return out
+	}
+	p := e.state.Players[idx]
+	if pending := e.state.Pending; pending != nil {
+		switch pending.Action {
+		case "discard_tokens":
+			out = append(out, legalDiscards(p.Tokens, pending.DiscardCount)...)
+		case "claim_noble":
+			for _, id := range pending.NobleIDs {
+				out = append(out, Action{Type: "claim_noble", Payload: ActionInput{NobleID: id}})
+			}
+		}
+		return out
+	}
+
+	out = append(out, e.legalTakes(p)...)
+	out = append(out, e.legalReserves(p)...)
+	out = append(out, e.legalBuys(p)...)
+	if e.state.Mode != ModeStrict || len(out) == 0 {
+		out = append(out, Action{Type: "pass"})
+	}
+	return out
+}
+
+// LegalActionsFor is LegalActions computed from a state alone, for callers
+// such as bots that only hold a snapshot.
+func LegalActionsFor(state State, playerID string) []Action {
+	return (&Engine{state: &state}).LegalActions(playerID)
+}
+
+func (e *Engine) legalTakes(p PlayerState) []Action {
+	var out []Action
+	available := make([]string, 0, len(ColoredGems))
+	for _, color := range ColoredGems {
+		if e.state.Bank.Get(color) > 0 {
+			available = append(available, color)
+		}
+	}
+
+	for size := 3; size >= 1; size-- {
+		for _, combo := range combinations(available, size) {
+			out = append(out, Action{Type: "take_tokens", Payload: ActionInput{Colors: combo}})
+		}
+	}
+	for _, color := range ColoredGems {
+		if e.state.Bank.Get(color) >= 4 {
+			out = append(out, Action{Type: "take_tokens", Payload: ActionInput{Colors: []string{color, color}}})
+		}
+	}
+	return out
+}
+
+func (e *Engine) legalReserves(p PlayerState) []Action {
+	var out []Action
+	if len(p.Reserved) >= e.state.Rules.ReserveLimit {
+		return out
+	}
+	for _, tier := range [][]Card{e.state.Tier1, e.state.Tier2, e.state.Tier3} {
+		for _, card := range tier {
+			out = append(out, Action{Type: "reserve_card", Payload: ActionInput{CardID: card.ID}})
+		}
+	}
+	for tier, count := range []int{e.state.Deck1Count, e.state.Deck2Count, e.state.Deck3Count} {
+		if count > 0 {
+			out = append(out, Action{Type: "reserve_card", Payload: ActionInput{Source: "deck", Tier: tier + 1}})
+		}
+	}
+	return out
+}
+
+func (e *Engine) legalBuys(p PlayerState) []Action {
+	var out []Action
+	for _, tier := range [][]Card{e.state.Tier1, e.state.Tier2, e.state.Tier3} {
+		for _, card := range tier {
+			if _, ok := calculatePayment(card.Cost, p.Bonuses, p.Tokens); ok {
+				out = append(out, Action{Type: "buy_card", Payload: ActionInput{CardID: card.ID, Source: "tableau"}})
+			}
+		}
+	}
+	for _, card := range p.Reserved {
+		if _, ok := calculatePayment(card.Cost, p.Bonuses, p.Tokens); ok {
+			out = append(out, Action{Type: "buy_card", Payload: ActionInput{CardID: card.ID, Source: "reserved"}})
+		}
+	}
+	return out
+}
+
+// legalDiscards lists every distinct way to give back count tokens.
+func legalDiscards(tokens TokenSet, count int) []Action {
+	colors := append(append([]string(nil), ColoredGems...), GemGold)
+	var out []Action
+	var walk func(start int, picked []string, held TokenSet)
+	walk = func(start int, picked []string, held TokenSet) {
+		if len(picked) == count {
+			out = append(out, Action{Type: "discard_tokens", Payload: ActionInput{Colors: append([]string(nil), picked...)}})
+			return
+		}
+		for i := start; i < len(colors); i++ {
+			if held.Get(colors[i]) == 0 {
+				continue
+			}
+			held.Sub(colors[i], 1)
+			walk(i, append(picked, colors[i]), held)
+			held.Add(colors[i], 1)
+		}
+	}
+	walk(0, make([]string, 0, count), tokens)
+	return out
+}
+
+// combinations returns every size-k subset of items, preserving item order.
+func combinations(items []string, k int) [][]string {
+	if k <= 0 || k > len(items) {
+		return nil
+	}
+	var out [][]string
+	var walk func(start int, picked []string)
+	walk = func(start int, picked []string) {
+		if len(picked) == k {
+			out = append(out, append([]string(nil), picked...))
+			return
+		}
+		for i := start; i < len(items); i++ {
+			walk(i+1, append(picked, items[i]))
+		}
+	}
+	walk(0, make([]string, 0, k))
+	return out
+}
@@ -0,0 +1,135 @@
+package game
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Replay rebuilds a standard-rules game from its seed, seats and action log.
+// The returned engine has the same state and log as the one that produced
+// the entries.
+func Replay(seed int64, seats []Seat, log []LogEntry) (*Engine, error) {
+	return ReplayWithOptions(Options{Seed: seed}, seats, log)
+}
+
+// ReplayWithOptions is Replay for games set up with non-default options.
+func ReplayWithOptions(opts Options, seats []Seat, log []LogEntry) (*Engine, error) {
+	e, err := NewWithOptions(seats, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range log {
+		if _, err := e.apply(entry.PlayerID, entry.Action, entry.At, entry.Timeout); err != nil {
+			return nil, fmt.Errorf("replay entry %d: %w", entry.Seq, err)
+		}
+		got := e.log[len(e.log)-1]
+		if got.Turn != entry.Turn || !reflect.DeepEqual(got.Delta, entry.Delta) {
+			return nil, fmt.Errorf("%w: entry %d", ErrReplayMismatch, entry.Seq)
+		}
+	}
+	return e, nil
+}
+
+// actionBaseline is what an action is diffed against when it is logged.
+type actionBaseline struct {
+	turn     int
+	playerID string
+	player   PlayerState
+	reserved []string
+	nobles   []string
+	bank     TokenSet
+}
+
+func (e *Engine) capture(playerID string) actionBaseline {
+	b := actionBaseline{turn: e.state.Turn, playerID: playerID, bank: e.state.Bank}
+	if idx := e.playerIndex(playerID); idx != -1 {
+		p := e.state.Players[idx]
+		b.player = p
+		b.reserved = cardIDs(p.Reserved)
+		b.nobles = nobleIDs(p.Nobles)
+	}
+	return b
+}
+
+func (e *Engine) record(before actionBaseline, action Action, at time.Time, timeout bool) {
+	delta := ActionDelta{}
+	if idx := e.playerIndex(before.playerID); idx != -1 {
+		p := e.state.Players[idx]
+		delta.Tokens = diffTokens(p.Tokens, before.player.Tokens)
+		delta.Bonuses = diffTokens(p.Bonuses, before.player.Bonuses)
+		delta.Points = p.Points - before.player.Points
+		delta.Reserved = addedIDs(cardIDs(p.Reserved), before.reserved)
+		delta.Nobles = addedIDs(nobleIDs(p.Nobles), before.nobles)
+	}
+	delta.Bank = diffTokens(e.state.Bank, before.bank)
+
+	e.log = append(e.log, LogEntry{
+		Seq:      len(e.log) + 1,
+		Turn:     before.turn,
+		PlayerID: before.playerID,
+		Action:   cloneAction(action),
+		At:       at,
+		Delta:    delta,
+		Timeout:  timeout,
+	})
+}
+
+func diffTokens(after, before TokenSet) TokenSet {
+	out := TokenSet{}
+	for _, color := range append(append([]string(nil), ColoredGems...), GemGold) {
+		out.Add(color, after.Get(color)-before.Get(color))
+	}
+	return out
+}
+
+func addedIDs(after, before []string) []string {
+	var out []string
+	for _, id := range after {
+		if !slices.Contains(before, id) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func cardIDs(cards []Card) []string {
+	out := make([]string, 0, len(cards))
+	for _, c := range cards {
+		out = append(out, c.ID)
+	}
+	return out
+}
+
+func nobleIDs(nobles []Noble) []string {
+	out := make([]string, 0, len(nobles))
+	for _, n := range nobles {
+		out = append(out, n.ID)
+	}
+	return out
+}
+
+func cloneAction(a Action) Action {
+	out := a
+	out.Payload.Colors = append([]string(nil), a.Payload.Colors...)
+	if a.Payload.Payment != nil {
+		payment := *a.Payload.Payment
+		out.Payload.Payment = &payment
+	}
+	if a.Payload.Adjust != nil {
+		out.Payload.Adjust = make(map[string]int, len(a.Payload.Adjust))
+		for k, v := range a.Payload.Adjust {
+			out.Payload.Adjust[k] = v
+		}
+	}
+	return out
+}
+
+func cloneLogEntry(entry LogEntry) LogEntry {
+	out := entry
+	out.Action = cloneAction(entry.Action)
+	out.Delta.Reserved = append([]string(nil), entry.Delta.Reserved...)
+	out.Delta.Nobles = append([]string(nil), entry.Delta.Nobles...)
+	return out
+}
@@ -0,0 +1,146 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidRules = errors.New("invalid rules")
+
+const (
+	PresetStandard = "standard"
+	PresetQuick    = "quick"
+	PresetLong     = "long"
+	PresetCustom   = "custom"
+)
+
+// RuleSet holds the numbers a game variant may change. The zero value is not
+// playable; start from DefaultRules or a preset.
+type RuleSet struct {
+	Preset       string `json:"preset"`
+	TargetScore  int    `json:"targetScore"`
+	TokenLimit   int    `json:"tokenLimit"`
+	ReserveLimit int    `json:"reserveLimit"`
+	GoldCount    int    `json:"goldCount"`
+	// BankTokens is the number of each colored token for 2, 3 and 4 players.
+	BankTokens [3]int `json:"bankTokens"`
+	// ExtraNobles is how many nobles are dealt beyond the player count.
+	ExtraNobles int `json:"extraNobles"`
+}
+
+// RuleOverrides changes individual fields of a preset. Nil fields keep the
+// preset value.
+type RuleOverrides struct {
+	TargetScore  *int    `json:"targetScore,omitempty"`
+	TokenLimit   *int    `json:"tokenLimit,omitempty"`
+	ReserveLimit *int    `json:"reserveLimit,omitempty"`
+	GoldCount    *int    `json:"goldCount,omitempty"`
+	BankTokens   *[3]int `json:"bankTokens,omitempty"`
+	ExtraNobles  *int    `json:"extraNobles,omitempty"`
+}
+
+// DefaultRules are the standard rules of the base game.
+func DefaultRules() RuleSet {
+	return RuleSet{
+		Preset:       PresetStandard,
+		TargetScore:  15,
+		TokenLimit:   10,
+		ReserveLimit: 3,
+		GoldCount:    5,
+		BankTokens:   [3]int{4, 5, 7},
+		ExtraNobles:  1,
+	}
+}
+
+// PresetRules returns the rules for a named preset. An empty name is the
+// standard game.
+func PresetRules(name string) (RuleSet, error) {
+	rules := DefaultRules()
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", PresetStandard:
+	case PresetQuick:
+		rules.Preset = PresetQuick
+		rules.TargetScore = 10
+	case PresetLong:
+		rules.Preset = PresetLong
+		rules.TargetScore = 21
+	default:
+		return RuleSet{}, fmt.Errorf("%w: unknown preset %q", ErrInvalidRules, name)
+	}
+	return rules, nil
+}
+
+// ResolveRules applies overrides on top of a preset and validates the result.
+// Rules that end up different from the preset are labelled custom.
+func ResolveRules(preset string, overrides RuleOverrides) (RuleSet, error) {
+	base, err := PresetRules(preset)
+	if err != nil {
+		return RuleSet{}, err
+	}
+	return base.Resolve(overrides)
+}
+
+// Resolve applies overrides on top of r and validates the result. Rules that
+// end up different from r are labelled custom.
+func (r RuleSet) Resolve(overrides RuleOverrides) (RuleSet, error) {
+	rules := r.With(overrides)
+	if rules != r {
+		rules.Preset = PresetCustom
+	}
+	if err := rules.Validate(); err != nil {
+		return RuleSet{}, err
+	}
+	return rules, nil
+}
+
+func (r RuleSet) With(o RuleOverrides) RuleSet {
+	if o.TargetScore != nil {
+		r.TargetScore = *o.TargetScore
+	}
+	if o.TokenLimit != nil {
+		r.TokenLimit = *o.TokenLimit
+	}
+	if o.ReserveLimit != nil {
+		r.ReserveLimit = *o.ReserveLimit
+	}
+	if o.GoldCount != nil {
+		r.GoldCount = *o.GoldCount
+	}
+	if o.BankTokens != nil {
+		r.BankTokens = *o.BankTokens
+	}
+	if o.ExtraNobles != nil {
+		r.ExtraNobles = *o.ExtraNobles
+	}
+	return r
+}
+
+func (r RuleSet) Validate() error {
+	if r.TargetScore < 1 || r.TargetScore > 30 {
+		return fmt.Errorf("%w: targetScore must be between 1 and 30", ErrInvalidRules)
+	}
+	if r.TokenLimit < 3 || r.TokenLimit > 20 {
+		return fmt.Errorf("%w: tokenLimit must be between 3 and 20", ErrInvalidRules)
+	}
+	if r.ReserveLimit < 0 || r.ReserveLimit > 5 {
+		return fmt.Errorf("%w: reserveLimit must be between 0 and 5", ErrInvalidRules)
+	}
+	if r.GoldCount < 0 || r.GoldCount > 10 {
+		return fmt.Errorf("%w: goldCount must be between 0 and 10", ErrInvalidRules)
+	}
+	for _, n := range r.BankTokens {
+		if n < 1 || n > 10 {
+			return fmt.Errorf("%w: bankTokens must be between 1 and 10", ErrInvalidRules)
+		}
+	}
+	if r.ExtraNobles < 0 || r.ExtraNobles+4 > len(noblesDataset()) {
+		return fmt.Errorf("%w: extraNobles must be between 0 and %d", ErrInvalidRules, len(noblesDataset())-4)
+	}
+	return nil
+}
+
+// bankTokens is the starting number of each colored token for n players.
+func (r RuleSet) bankTokens(players int) int {
+	return r.BankTokens[players-2]
+}
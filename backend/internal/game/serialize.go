@@ -0,0 +1,76 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// serializationVersion is bumped whenever the encoded layout changes in a
+// way older readers cannot handle.
+const serializationVersion = 1
+
+var ErrInvalidSerialization = errors.New("invalid engine serialization")
+
+// savedEngine is the encoded form of a full engine, hidden decks included.
+type savedEngine struct {
+	Version         int        `json:"version"`
+	Seed            int64      `json:"seed"`
+	CheckInvariants bool       `json:"checkInvariants,omitempty"`
+	State           State      `json:"state"`
+	Hidden          Hidden     `json:"hidden"`
+	Log             []LogEntry `json:"log"`
+}
+
+// Marshal encodes the complete engine: public state, hidden deck order, seed
+// and action log. The output contains secret information and must not be
+// sent to players.
+func (e *Engine) Marshal() ([]byte, error) {
+	return json.Marshal(savedEngine{
+		Version:         serializationVersion,
+		Seed:            e.seed,
+		CheckInvariants: e.verify,
+		State:           e.Snapshot(),
+		Hidden:          e.hidden(),
+		Log:             e.Log(),
+	})
+}
+
+// Restore rebuilds an engine from Marshal output. The restored state must
+// pass CheckInvariants.
+func Restore(data []byte) (*Engine, error) {
+	var saved savedEngine
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSerialization, err)
+	}
+	if saved.Version != serializationVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSerialization, saved.Version)
+	}
+	if err := CheckInvariants(saved.State, saved.Hidden); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidSerialization, err)
+	}
+
+	state := saved.State
+	return &Engine{
+		state:  &state,
+		seed:   saved.Seed,
+		log:    saved.Log,
+		verify: saved.CheckInvariants,
+		deck1:  saved.Hidden.Deck1,
+		deck2:  saved.Hidden.Deck2,
+		deck3:  saved.Hidden.Deck3,
+	}, nil
+}
+
+func (e *Engine) MarshalBinary() ([]byte, error) {
+	return e.Marshal()
+}
+
+func (e *Engine) UnmarshalBinary(data []byte) error {
+	restored, err := Restore(data)
+	if err != nil {
+		return err
+	}
+	*e = *restored
+	return nil
+}
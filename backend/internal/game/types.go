@@ -1,12 +1,31 @@
 package game
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 const (
 	StatusPlaying  = "playing"
 	StatusFinished = "finished"
 )
 
+// Modes decide how closely a game follows the printed rules. Strict games
+// only accept rule moves; sandbox games also allow free-form token
+// adjustment and passing, which is handy for teaching.
+const (
+	ModeStrict  = "strict"
+	ModeSandbox = "sandbox"
+)
+
+// Phases within a turn. A turn normally resolves in PhaseAction; some moves
+// leave the current player with a pending decision to make first.
+const (
+	PhaseAction          = "action"
+	PhaseAwaitingDiscard = "awaiting_discard"
+	PhaseChooseNoble     = "choose_noble"
+)
+
 const (
 	GemWhite = "white"
 	GemBlue  = "blue"
@@ -77,6 +96,9 @@ type Card struct {
 	Bonus  string   `json:"bonus"`
 	Points int      `json:"points"`
 	Cost   TokenSet `json:"cost"`
+	// Blind marks a card reserved face-down from the top of a deck. Only the
+	// owner sees its face; everyone else gets the tier alone.
+	Blind bool `json:"blind,omitempty"`
 }
 
 type Noble struct {
@@ -92,10 +114,13 @@ type PlayerState struct {
 	Bonuses        TokenSet  `json:"bonuses"`
 	Reserved       []Card    `json:"reserved"`
 	PurchasedCount int       `json:"purchasedCount"`
+	PurchasedIDs   []string  `json:"purchasedIds"`
 	Points         int       `json:"points"`
 	Nobles         []Noble   `json:"nobles"`
 	IsConnected    bool      `json:"isConnected"`
 	LastAction     string    `json:"lastAction"`
+	// Resigned players are out of the turn rotation and cannot win.
+	Resigned bool `json:"resigned,omitempty"`
 }
 
 type State struct {
@@ -114,6 +139,22 @@ type State struct {
 	WinnerIDs       []string      `json:"winnerIds"`
 	FinalRound      bool          `json:"finalRound"`
 	FinalTurnsLeft  int           `json:"finalTurnsLeft"`
+	Phase           string        `json:"phase"`
+	Pending         *Pending      `json:"pending,omitempty"`
+	Rules           RuleSet       `json:"rules"`
+	Mode            string        `json:"mode"`
+	// CardSet is the custom deck the game was dealt from; nil means the
+	// standard 90 cards. It is never modified once the game starts.
+	CardSet []Card `json:"cardSet,omitempty"`
+}
+
+// Pending is a decision the current player must make before the turn ends.
+type Pending struct {
+	PlayerID string `json:"playerId"`
+	// Action is the only action type accepted until the decision is made.
+	Action       string   `json:"action"`
+	DiscardCount int      `json:"discardCount,omitempty"`
+	NobleIDs     []string `json:"nobleIds,omitempty"`
 }
 
 type Seat struct {
@@ -127,8 +168,79 @@ type Action struct {
 }
 
 type ActionInput struct {
-	Colors []string       `json:"colors,omitempty"`
-	Adjust map[string]int `json:"adjust,omitempty"`
-	CardID string         `json:"cardId,omitempty"`
-	Source string         `json:"source,omitempty"`
+	Colors  []string       `json:"colors,omitempty"`
+	Adjust  map[string]int `json:"adjust,omitempty"`
+	CardID  string         `json:"cardId,omitempty"`
+	Source  string         `json:"source,omitempty"`
+	Tier    int            `json:"tier,omitempty"`
+	NobleID string         `json:"nobleId,omitempty"`
+	// Payment optionally chooses the tokens spent by buy_card. Gold stands
+	// in one-for-one for any color; omitted means colored tokens first.
+	Payment *TokenSet `json:"payment,omitempty"`
+}
+
+// PurchasePreview describes what buying a card would cost a player.
+type PurchasePreview struct {
+	CardID     string `json:"cardId"`
+	Source     string `json:"source"`
+	Affordable bool   `json:"affordable"`
+	// Payment is what buy_card would spend without an explicit payment. It is
+	// empty when the card is not affordable.
+	Payment TokenSet `json:"payment"`
+	// Shortfall holds, per color, what bonuses and colored tokens leave
+	// uncovered. Its Gold field is how much of that held gold cannot cover.
+	Shortfall TokenSet `json:"shortfall"`
+}
+
+// Event types reported by Apply.
+const (
+	EventTokensTaken       = "tokens_taken"
+	EventTokensDiscarded   = "tokens_discarded"
+	EventTokensAdjusted    = "tokens_adjusted"
+	EventCardReserved      = "card_reserved"
+	EventCardBought        = "card_bought"
+	EventCardRevealed      = "card_revealed"
+	EventNobleClaimed      = "noble_claimed"
+	EventFinalRoundStarted = "final_round_started"
+	EventGameFinished      = "game_finished"
+	EventPlayerResigned    = "player_resigned"
+)
+
+// Event is one thing an applied action changed, in the order it happened,
+// so clients can animate or narrate a move without diffing snapshots. Only
+// the fields relevant to Type are set.
+type Event struct {
+	Type     string `json:"type"`
+	PlayerID string `json:"playerId,omitempty"`
+	// Tokens is what moved between the player and the bank: the tokens
+	// taken, discarded or paid for a card, the gold that came with a
+	// reservation, or the signed change of adjust_tokens.
+	Tokens *TokenSet `json:"tokens,omitempty"`
+	Card   *Card     `json:"card,omitempty"`
+	// Source is where a reserved or bought card came from.
+	Source    string   `json:"source,omitempty"`
+	Noble     *Noble   `json:"noble,omitempty"`
+	WinnerIDs []string `json:"winnerIds,omitempty"`
+}
+
+// LogEntry records one accepted action together with what it changed for the
+// acting player and the bank.
+type LogEntry struct {
+	Seq      int         `json:"seq"`
+	Turn     int         `json:"turn"`
+	PlayerID string      `json:"playerId"`
+	Action   Action      `json:"action"`
+	At       time.Time   `json:"at"`
+	Delta    ActionDelta `json:"delta"`
+	// Timeout marks moves the engine played because the turn timer ran out.
+	Timeout bool `json:"timeout,omitempty"`
+}
+
+type ActionDelta struct {
+	Tokens   TokenSet `json:"tokens"`
+	Bank     TokenSet `json:"bank"`
+	Bonuses  TokenSet `json:"bonuses"`
+	Points   int      `json:"points"`
+	Reserved []string `json:"reserved,omitempty"`
+	Nobles   []string `json:"nobles,omitempty"`
 }
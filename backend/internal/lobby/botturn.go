@@ -0,0 +1,83 @@
+package lobby
+
+import (
+	"errors"
+	"time"
+
+	"splendor/backend/internal/bot"
+	"splendor/backend/internal/game"
+)
+
+var ErrStaleBotTurn = errors.New("room changed while the bot was thinking")
+
+// BotTurn is the move of a searching bot that is on turn. Searches take far
+// longer than a greedy move, so instead of playing them under the store lock
+// the caller runs Choose unlocked and hands the result to ApplyBotMove.
+type BotTurn struct {
+	RoomID   string
+	PlayerID string
+	// State is what the bot's seat may see.
+	State   game.State
+	policy  bot.Policy
+	version uint64
+}
+
+// Choose runs the bot's search. The store is not locked meanwhile.
+func (t BotTurn) Choose() game.Action {
+	return t.policy.Choose(t.State, t.PlayerID)
+}
+
+// searching reports whether a bot searches for its moves; those are left for
+// BotTurn rather than played by playBotsLocked.
+func searching(policy bot.Policy) bool {
+	_, ok := policy.(*bot.MCTS)
+	return ok
+}
+
+// BotTurn returns the move a searching bot owes in the room, if any.
+func (s *Store) BotTurn(roomRef string) (BotTurn, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok || room.Status != RoomPlaying || room.Engine == nil {
+		return BotTurn{}, false
+	}
+	state := room.Engine.Snapshot()
+	policy := room.Bots[state.CurrentPlayerID]
+	if policy == nil || !searching(policy) {
+		return BotTurn{}, false
+	}
+	return BotTurn{
+		RoomID:   room.ID,
+		PlayerID: state.CurrentPlayerID,
+		State:    state.RedactedFor(state.CurrentPlayerID),
+		policy:   policy,
+		version:  room.Version,
+	}, true
+}
+
+// ApplyBotMove plays the action chosen for turn, then any greedy bot moves
+// it sets off. The move is dropped with ErrStaleBotTurn when the room
+// changed after the turn was taken.
+func (s *Store) ApplyBotMove(turn BotTurn, action game.Action) (*Room, []game.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.resolveRoomLocked(turn.RoomID)
+	if !ok {
+		return nil, nil, ErrRoomNotFound
+	}
+	if room.Version != turn.version || room.Status != RoomPlaying || room.Engine.CurrentPlayerID() != turn.PlayerID {
+		return nil, nil, ErrStaleBotTurn
+	}
+
+	now := time.Now().UTC()
+	events, err := playBotMoveLocked(room, turn.PlayerID, action, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	events = append(events, playBotsLocked(room, now)...)
+	room.Version++
+	return snapshotRoom(room), events, nil
+}
@@ -0,0 +1,94 @@
+package lobby
+
+import (
+	"sort"
+	"time"
+)
+
+// RoomSummary is how a public room appears in the room browser.
+type RoomSummary struct {
+	ID         string     `json:"id"`
+	Code       string     `json:"code"`
+	HostName   string     `json:"hostName"`
+	Status     RoomStatus `json:"status"`
+	Players    int        `json:"players"`
+	MaxPlayers int        `json:"maxPlayers"`
+	RulePreset string     `json:"rulePreset"`
+	Mode       string     `json:"mode"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	AgeSeconds int        `json:"ageSeconds"`
+}
+
+// RoomFilter narrows ListRooms. Zero fields match every room.
+type RoomFilter struct {
+	Status RoomStatus
+	// HasSeat keeps only rooms with a free seat.
+	HasSeat bool
+}
+
+// Public reports whether the room may be listed in the room browser.
+func (r *Room) Public() bool {
+	return r.Visibility == VisibilityPublic && r.Status != RoomClosed
+}
+
+// Summary returns the room's browser listing as of now.
+func (r *Room) Summary(now time.Time) RoomSummary {
+	out := RoomSummary{
+		ID:         r.ID,
+		Code:       r.Code,
+		Status:     r.Status,
+		Players:    len(r.Players),
+		MaxPlayers: r.MaxPlayers,
+		RulePreset: r.Rules.Preset,
+		Mode:       r.Mode,
+		CreatedAt:  r.CreatedAt,
+		AgeSeconds: int(now.Sub(r.CreatedAt).Seconds()),
+	}
+	for _, p := range r.Players {
+		if p.ID == r.HostID {
+			out.HostName = p.Name
+		}
+	}
+	return out
+}
+
+// ListRooms returns the public rooms matching filter, newest first. Private
+// rooms are never listed.
+func (s *Store) ListRooms(filter RoomFilter, now time.Time) []RoomSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]RoomSummary, 0)
+	for _, room := range s.rooms {
+		if room.Visibility != VisibilityPublic {
+			continue
+		}
+		if filter.Status != "" && room.Status != filter.Status {
+			continue
+		}
+		if filter.HasSeat && (room.Status != RoomWaiting || len(room.Players) >= room.MaxPlayers) {
+			continue
+		}
+		// Only the listed fields are copied; a full snapshot would copy the
+		// game state of every room.
+		header := Room{
+			ID:         room.ID,
+			Code:       room.Code,
+			HostID:     room.HostID,
+			Status:     room.Status,
+			Rules:      room.Rules,
+			Mode:       room.Mode,
+			MaxPlayers: room.MaxPlayers,
+			Players:    room.Players,
+			CreatedAt:  room.CreatedAt,
+		}
+		out = append(out, header.Summary(now))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
@@ -0,0 +1,196 @@
+package lobby
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+)
+
+var (
+	ErrInvalidTarget    = errors.New("invalid target player")
+	ErrInvalidSeatOrder = errors.New("invalid seat order")
+)
+
+// KickPlayer removes a player or bot seat from a waiting room. Only the host
+// may kick, and not themselves.
+func (s *Store) KickPlayer(roomRef, hostID, targetID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+	targetID = strings.TrimSpace(targetID)
+	if targetID == room.HostID {
+		return nil, ErrInvalidTarget
+	}
+	if !containsPlayer(room.Players, targetID) {
+		return nil, ErrPlayerNotFound
+	}
+
+	removePlayerLocked(room, targetID)
+	room.Version++
+	return snapshotRoom(room), nil
+}
+
+// TransferHost hands the host role to another human player in the room who
+// is still in the game.
+func (s *Store) TransferHost(roomRef, hostID, targetID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	targetID = strings.TrimSpace(targetID)
+	idx := playerIndex(room.Players, targetID)
+	if idx == -1 {
+		return nil, ErrPlayerNotFound
+	}
+	if targetID == room.HostID || !canHostLocked(room, room.Players[idx]) {
+		return nil, ErrInvalidTarget
+	}
+
+	room.HostID = targetID
+	room.Version++
+	return snapshotRoom(room), nil
+}
+
+// SetSeatOrder reorders the seats of a waiting room. order must list every
+// player ID exactly once; the first seat moves first.
+func (s *Store) SetSeatOrder(roomRef, hostID string, order []string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+	if len(order) != len(room.Players) {
+		return nil, ErrInvalidSeatOrder
+	}
+
+	seated := make([]Player, 0, len(order))
+	for _, id := range order {
+		idx := playerIndex(room.Players, strings.TrimSpace(id))
+		if idx == -1 || containsPlayer(seated, room.Players[idx].ID) {
+			return nil, ErrInvalidSeatOrder
+		}
+		seated = append(seated, room.Players[idx])
+	}
+
+	room.Players = seated
+	room.Version++
+	return snapshotRoom(room), nil
+}
+
+// ShuffleSeats puts the seats of a waiting room in a random order.
+func (s *Store) ShuffleSeats(roomRef, hostID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+
+	rand.Shuffle(len(room.Players), func(i, j int) {
+		room.Players[i], room.Players[j] = room.Players[j], room.Players[i]
+	})
+	room.Version++
+	return snapshotRoom(room), nil
+}
+
+// CloseRoom closes a waiting room and deletes it, seats and all. Only the
+// host may close the room.
+func (s *Store) CloseRoom(roomRef, hostID string) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+
+	s.closeRoomLocked(room)
+	return snapshotRoom(room), nil
+}
+
+func (s *Store) closeRoomLocked(room *roomEntity) {
+	room.Status = RoomClosed
+	room.Version++
+	delete(s.rooms, room.ID)
+	delete(s.codeToID, room.Code)
+}
+
+func (s *Store) hostRoomLocked(roomRef, hostID string) (*roomEntity, error) {
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	if room.HostID != strings.TrimSpace(hostID) {
+		return nil, ErrOnlyHost
+	}
+	return room, nil
+}
+
+// passHostLocked gives the host role to the first other human still in the
+// game, when the host leaves. It reports whether the host changed; a room
+// with nobody to take over keeps its host.
+func passHostLocked(room *roomEntity) bool {
+	for _, p := range room.Players {
+		if p.ID != room.HostID && canHostLocked(room, p) {
+			room.HostID = p.ID
+			return true
+		}
+	}
+	return false
+}
+
+// canHostLocked reports whether a player may hold the host role: a human
+// who has not resigned or left the game.
+func canHostLocked(room *roomEntity, p Player) bool {
+	if p.Bot {
+		return false
+	}
+	if room.Engine == nil {
+		return true
+	}
+	for _, state := range room.Engine.Snapshot().Players {
+		if state.ID == p.ID {
+			return !state.Resigned
+		}
+	}
+	return true
+}
+
+// removePlayerLocked drops a seat from a room that has not started.
+func removePlayerLocked(room *roomEntity, playerID string) {
+	if idx := playerIndex(room.Players, playerID); idx != -1 {
+		room.Players = append(room.Players[:idx], room.Players[idx+1:]...)
+	}
+	delete(room.Bots, playerID)
+}
+
+func playerIndex(players []Player, playerID string) int {
+	for i, p := range players {
+		if p.ID == playerID {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,7 @@
+//go:build debug
+
+package lobby
+
+// Debug builds check the game of every room, not only strict ones, after
+// every applied action.
+const debugInvariants = true
@@ -0,0 +1,6 @@
+//go:build !debug
+
+package lobby
+
+// Release builds only check the games of strict rooms.
+const debugInvariants = false
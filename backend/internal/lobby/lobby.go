@@ -2,11 +2,13 @@ package lobby
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
 	"strings"
 	"sync"
 	"time"
 
+	"splendor/backend/internal/bot"
 	"splendor/backend/internal/game"
 )
 
@@ -17,39 +19,111 @@ var (
 	ErrPlayerNotFound     = errors.New("player not found")
 	ErrInvalidTurnSeconds = errors.New("invalid turn seconds")
 	ErrOnlyHostCanStart   = errors.New("only host can start")
+	ErrOnlyHost           = errors.New("only host can manage the room")
 	ErrInvalidStartState  = errors.New("cannot start game in current room state")
 	ErrGameNotStarted     = errors.New("game not started")
 	ErrGameAlreadyStarted = errors.New("game already started")
+	ErrRoomQuarantined    = errors.New("room quarantined after a game state error")
+	ErrInvalidTimeout     = errors.New("invalid timeout policy")
+	ErrPlayersNotReady    = errors.New("not every player is ready")
 )
 
 const MaxPlayers = 4
 const DefaultTurnSeconds = 30
 
+// maxBotMoves bounds how many bot moves one call plays in a row, so a faulty
+// policy cannot hold the store lock indefinitely. Games never run with bots
+// alone; see endBotOnlyGameLocked.
+const maxBotMoves = 32
+
 type RoomStatus string
 
 const (
 	RoomWaiting  RoomStatus = "waiting"
 	RoomPlaying  RoomStatus = "playing"
 	RoomFinished RoomStatus = "finished"
+	// RoomQuarantined rooms hit an invariant violation and accept no moves.
+	RoomQuarantined RoomStatus = "quarantined"
+	// RoomClosed rooms were left by everyone before the game started.
+	RoomClosed RoomStatus = "closed"
+)
+
+// Timeout policies decide what happens when a player's turn timer runs out.
+const (
+	// TimeoutPass resolves pending decisions automatically and passes.
+	TimeoutPass = "pass"
+	// TimeoutBot lets the built-in bot play the move.
+	TimeoutBot = "bot"
+	// TimeoutForfeit passes like TimeoutPass, but after ForfeitAfter
+	// consecutive timeouts the seat is handed to a bot for the rest of the
+	// game.
+	TimeoutForfeit = "forfeit_after_n"
+)
+
+const defaultForfeitAfter = 3
+
+// Reasons reported with a TimeoutUpdate.
+const (
+	ReasonTurnTimeout  = "turn_timeout"
+	ReasonTimeoutBot   = "timeout_bot_move"
+	ReasonSeatForfeits = "seat_forfeited"
 )
 
+type TimeoutPolicy struct {
+	Mode string `json:"mode"`
+	// ForfeitAfter is N for TimeoutForfeit.
+	ForfeitAfter int `json:"forfeitAfter,omitempty"`
+}
+
 type Player struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	// Bot seats are played by the store with the built-in policy.
+	Bot        bool   `json:"bot,omitempty"`
+	Difficulty string `json:"difficulty,omitempty"`
+	// Forfeited marks a human seat handed to a bot after repeated timeouts.
+	Forfeited bool `json:"forfeited,omitempty"`
+	// Ready is set by the player in the waiting room; bots are always ready.
+	Ready bool `json:"ready"`
 }
 
 type Room struct {
-	ID          string      `json:"id"`
-	Code        string      `json:"code"`
-	HostID      string      `json:"hostId"`
-	Status      RoomStatus  `json:"status"`
-	TurnSeconds int         `json:"turnSeconds"`
-	TurnDeadline *time.Time `json:"turnDeadline,omitempty"`
-	Players     []Player    `json:"players"`
-	CreatedAt   time.Time   `json:"createdAt"`
-	StartedAt   *time.Time  `json:"startedAt,omitempty"`
-	FinishedAt  *time.Time  `json:"finishedAt,omitempty"`
-	Game        *game.State `json:"game,omitempty"`
+	ID            string        `json:"id"`
+	Code          string        `json:"code"`
+	HostID        string        `json:"hostId"`
+	Status        RoomStatus    `json:"status"`
+	TurnSeconds   int           `json:"turnSeconds"`
+	TurnDeadline  *time.Time    `json:"turnDeadline,omitempty"`
+	Rules         game.RuleSet  `json:"rules"`
+	Mode          string        `json:"mode"`
+	TimeoutPolicy TimeoutPolicy `json:"timeoutPolicy"`
+	MaxPlayers    int           `json:"maxPlayers"`
+	Visibility    string        `json:"visibility"`
+	HasPassword   bool          `json:"hasPassword"`
+	AutoStart     bool          `json:"autoStart"`
+	Players       []Player      `json:"players"`
+	CreatedAt     time.Time     `json:"createdAt"`
+	StartedAt     *time.Time    `json:"startedAt,omitempty"`
+	FinishedAt    *time.Time    `json:"finishedAt,omitempty"`
+	Seed          *int64        `json:"seed,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Game          *game.State   `json:"game,omitempty"`
+	LogLimit      int           `json:"logLimit"`
+	Log           []LogLine     `json:"log"`
+	// Version goes up with every change to the room, so of two snapshots
+	// the one with the higher Version is the newer.
+	Version uint64 `json:"version"`
+}
+
+// RedactedFor returns a copy of the room as playerID may see it. Pass an
+// empty ID for spectators.
+func (r *Room) RedactedFor(playerID string) *Room {
+	out := *r
+	if r.Game != nil {
+		g := r.Game.RedactedFor(playerID)
+		out.Game = &g
+	}
+	return &out
 }
 
 type roomEntity struct {
@@ -59,15 +133,68 @@ type roomEntity struct {
 	Status       RoomStatus
 	TurnSeconds  int
 	TurnDeadline *time.Time
+	Rules        game.RuleSet
+	Mode         string
+	Timeout      TimeoutPolicy
+	MaxPlayers   int
+	Visibility   string
+	Password     roomPassword
+	AutoStart    bool
 	Players      []Player
 	CreatedAt    time.Time
 	StartedAt    *time.Time
 	FinishedAt   *time.Time
+	Error        string
 	Engine       *game.Engine
+	Bots         map[string]bot.Policy
+	// Timeouts counts each player's consecutive timed-out turns.
+	Timeouts map[string]int
+	// Log keeps the last LogLimit lines; LogSeq numbers them.
+	Log      []LogLine
+	LogLimit int
+	LogSeq   int
+	Version  uint64
+}
+
+// RoomOptions configures a room at creation.
+type RoomOptions struct {
+	TurnSeconds int
+	// Rules is the variant played in the room; the zero value means the
+	// standard rules.
+	Rules game.RuleSet
+	// Mode is game.ModeStrict or game.ModeSandbox; empty means sandbox.
+	Mode string
+	// TimeoutPolicy defaults to TimeoutPass.
+	TimeoutPolicy TimeoutPolicy
+	// LogLimit is how many game log lines the room keeps; zero means
+	// DefaultLogLimit.
+	LogLimit int
+	// MaxPlayers is the number of seats, 2 to 4; zero means 4.
+	MaxPlayers int
+	// Visibility is VisibilityPublic (the default) or VisibilityPrivate.
+	Visibility string
+	// Password, when set, is required to join; such rooms are always
+	// private.
+	Password string
+	// AutoStart starts the game as soon as every seat is taken and every
+	// player but the host is ready.
+	AutoStart bool
+}
+
+// StartOptions carries optional parameters for StartGame.
+type StartOptions struct {
+	// Seed fixes the deck and noble shuffle; nil picks a random seed.
+	Seed *int64
+	// Force lets the host start before everyone is ready.
+	Force bool
 }
 
 type TimeoutUpdate struct {
 	Room *Room
+	// Reason is one of the Reason constants and tells what the timeout did.
+	Reason string
+	// Events are those of the timeout move and any bot moves after it.
+	Events []game.Event
 }
 
 type Store struct {
@@ -83,7 +210,8 @@ func NewStore() *Store {
 	}
 }
 
-func normalizeTurnSeconds(raw int) (int, error) {
+// NormalizeTurnSeconds applies the default turn timer and checks its bounds.
+func NormalizeTurnSeconds(raw int) (int, error) {
 	if raw == 0 {
 		return DefaultTurnSeconds, nil
 	}
@@ -93,11 +221,76 @@ func normalizeTurnSeconds(raw int) (int, error) {
 	return raw, nil
 }
 
+func normalizeTimeoutPolicy(policy TimeoutPolicy) (TimeoutPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(policy.Mode)) {
+	case "", TimeoutPass:
+		return TimeoutPolicy{Mode: TimeoutPass}, nil
+	case TimeoutBot:
+		return TimeoutPolicy{Mode: TimeoutBot}, nil
+	case TimeoutForfeit:
+		n := policy.ForfeitAfter
+		if n == 0 {
+			n = defaultForfeitAfter
+		}
+		if n < 1 || n > 10 {
+			return TimeoutPolicy{}, ErrInvalidTimeout
+		}
+		return TimeoutPolicy{Mode: TimeoutForfeit, ForfeitAfter: n}, nil
+	default:
+		return TimeoutPolicy{}, ErrInvalidTimeout
+	}
+}
+
+func normalizeRules(rules game.RuleSet) (game.RuleSet, error) {
+	if rules == (game.RuleSet{}) {
+		return game.DefaultRules(), nil
+	}
+	if err := rules.Validate(); err != nil {
+		return game.RuleSet{}, err
+	}
+	return rules, nil
+}
+
 func (s *Store) CreateRoom(hostName string, turnSeconds int) (*Room, error) {
-	normalized, err := normalizeTurnSeconds(turnSeconds)
+	return s.CreateRoomWithOptions(hostName, RoomOptions{TurnSeconds: turnSeconds})
+}
+
+func (s *Store) CreateRoomWithOptions(hostName string, opts RoomOptions) (*Room, error) {
+	normalized, err := NormalizeTurnSeconds(opts.TurnSeconds)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := normalizeRules(opts.Rules)
 	if err != nil {
 		return nil, err
 	}
+	mode, err := game.NormalizeMode(opts.Mode)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := normalizeTimeoutPolicy(opts.TimeoutPolicy)
+	if err != nil {
+		return nil, err
+	}
+	logLimit, err := normalizeLogLimit(opts.LogLimit)
+	if err != nil {
+		return nil, err
+	}
+	maxPlayers, err := normalizeMaxPlayers(opts.MaxPlayers)
+	if err != nil {
+		return nil, err
+	}
+	visibility, err := normalizeVisibility(opts.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	password, err := newRoomPassword(opts.Password)
+	if err != nil {
+		return nil, err
+	}
+	if password.set() {
+		visibility = VisibilityPrivate
+	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -111,8 +304,17 @@ func (s *Store) CreateRoom(hostName string, turnSeconds int) (*Room, error) {
 		HostID:      host.ID,
 		Status:      RoomWaiting,
 		TurnSeconds: normalized,
+		Rules:       rules,
+		Mode:        mode,
+		Timeout:     timeout,
+		MaxPlayers:  maxPlayers,
+		Visibility:  visibility,
+		Password:    password,
+		AutoStart:   opts.AutoStart,
+		LogLimit:    logLimit,
 		Players:     []Player{host},
 		CreatedAt:   time.Now().UTC(),
+		Version:     1,
 	}
 
 	s.rooms[roomID] = room
@@ -121,6 +323,19 @@ func (s *Store) CreateRoom(hostName string, turnSeconds int) (*Room, error) {
 }
 
 func (s *Store) JoinRoom(roomRef, playerName string) (*Room, Player, error) {
+	return s.JoinRoomWithPassword(roomRef, playerName, "")
+}
+
+// JoinRoomWithPassword joins a room that may be password-protected.
+func (s *Store) JoinRoomWithPassword(roomRef, playerName, password string) (*Room, Player, error) {
+	hashed, ok := s.roomPasswordOf(roomRef)
+	if !ok {
+		return nil, Player{}, ErrRoomNotFound
+	}
+	if !hashed.matches(password) {
+		return nil, Player{}, ErrInvalidPassword
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -138,16 +353,85 @@ func (s *Store) JoinRoom(roomRef, playerName string) (*Room, Player, error) {
 		}
 	}
 
-	if len(room.Players) >= MaxPlayers {
+	if len(room.Players) >= room.MaxPlayers {
 		return nil, Player{}, ErrRoomFull
 	}
 
 	player := Player{ID: randomCode(8), Name: strings.TrimSpace(playerName)}
 	room.Players = append(room.Players, player)
+	room.Version++
+	return snapshotRoom(room), player, nil
+}
+
+// AddBot fills a free seat in a waiting room with a bot. Only the host may
+// add bots.
+func (s *Store) AddBot(roomRef, playerID, difficulty string) (*Room, Player, error) {
+	normalized, err := bot.NormalizeDifficulty(difficulty)
+	if err != nil {
+		return nil, Player{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return nil, Player{}, ErrRoomNotFound
+	}
+	if room.Status != RoomWaiting {
+		return nil, Player{}, ErrGameAlreadyStarted
+	}
+	if room.HostID != strings.TrimSpace(playerID) {
+		return nil, Player{}, ErrOnlyHost
+	}
+	if len(room.Players) >= room.MaxPlayers {
+		return nil, Player{}, ErrRoomFull
+	}
+
+	policy, err := bot.New(normalized, time.Now().UnixNano())
+	if err != nil {
+		return nil, Player{}, err
+	}
+	player := Player{ID: randomCode(8), Name: botName(room.Players), Bot: true, Difficulty: normalized, Ready: true}
+	room.Players = append(room.Players, player)
+	if room.Bots == nil {
+		room.Bots = make(map[string]bot.Policy)
+	}
+	room.Bots[player.ID] = policy
+	room.Version++
+	if err := autoStartLocked(room); err != nil {
+		return nil, Player{}, err
+	}
 	return snapshotRoom(room), player, nil
 }
 
-func (s *Store) StartGame(roomRef, playerID string) (*Room, error) {
+// SetReady marks a player in a waiting room ready or not. It may start the
+// game in an AutoStart room.
+func (s *Store) SetReady(roomRef, playerID string, ready bool) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+	idx := playerIndex(room.Players, strings.TrimSpace(playerID))
+	if idx == -1 {
+		return nil, ErrPlayerNotFound
+	}
+
+	room.Players[idx].Ready = ready
+	room.Version++
+	if err := autoStartLocked(room); err != nil {
+		return nil, err
+	}
+	return snapshotRoom(room), nil
+}
+
+func (s *Store) StartGame(roomRef, playerID string, opts StartOptions) (*Room, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -164,56 +448,216 @@ func (s *Store) StartGame(roomRef, playerID string) (*Room, error) {
 	if len(room.Players) < 2 {
 		return nil, ErrInvalidStartState
 	}
+	if !opts.Force && !allReady(room.Players, room.HostID) {
+		return nil, ErrPlayersNotReady
+	}
+	if err := startGameLocked(room, opts); err != nil {
+		return nil, err
+	}
+	return snapshotRoom(room), nil
+}
+
+// autoStartLocked starts an AutoStart room once it is full and everyone but
+// the host is ready, the same readiness /start asks for.
+func autoStartLocked(room *roomEntity) error {
+	if !room.AutoStart || room.Status != RoomWaiting || len(room.Players) < room.MaxPlayers || !allReady(room.Players, room.HostID) {
+		return nil
+	}
+	return startGameLocked(room, StartOptions{})
+}
+
+// allReady reports whether every player other than except is ready.
+func allReady(players []Player, except string) bool {
+	for _, p := range players {
+		if p.ID != except && !p.Ready {
+			return false
+		}
+	}
+	return true
+}
 
+// startGameLocked deals a new game to the room's seats in seat order.
+func startGameLocked(room *roomEntity, opts StartOptions) error {
 	seats := make([]game.Seat, 0, len(room.Players))
 	for _, p := range room.Players {
 		seats = append(seats, game.Seat{ID: p.ID, Name: p.Name})
 	}
 
-	engine, err := game.New(seats)
+	seed := time.Now().UnixNano()
+	if opts.Seed != nil {
+		seed = *opts.Seed
+	}
+	engine, err := game.NewWithOptions(seats, game.Options{
+		Seed:  seed,
+		Rules: room.Rules,
+		Mode:  room.Mode,
+		// Strict rooms pay for a full consistency check after every move,
+		// and so does every room of a debug build.
+		CheckInvariants: room.Mode == game.ModeStrict || debugInvariants,
+	})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	now := time.Now().UTC()
+	room.Version++
 	room.Engine = engine
 	room.StartedAt = &now
 	room.Status = RoomPlaying
 	room.TurnDeadline = ptrTime(now.Add(time.Duration(room.TurnSeconds) * time.Second))
-	return snapshotRoom(room), nil
+	first := room.playerName(engine.CurrentPlayerID())
+	room.appendLog(now, "game_started", map[string]any{"player": first},
+		fmt.Sprintf("Game started; %s goes first", first))
+	playBotsLocked(room, now)
+	return nil
 }
 
-func (s *Store) ApplyAction(roomRef, playerID string, action game.Action) (*Room, error) {
+// ApplyAction plays a player's move. The events are those of the move
+// followed by those of any bot moves it set off, unredacted.
+func (s *Store) ApplyAction(roomRef, playerID string, action game.Action) (*Room, []game.Event, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	room, ok := s.resolveRoomLocked(roomRef)
 	if !ok {
-		return nil, ErrRoomNotFound
+		return nil, nil, ErrRoomNotFound
 	}
 	if room.Engine == nil {
-		return nil, ErrGameNotStarted
+		return nil, nil, ErrGameNotStarted
+	}
+	if room.Status == RoomQuarantined {
+		return nil, nil, ErrRoomQuarantined
 	}
 	if !containsPlayer(room.Players, playerID) {
-		return nil, ErrPlayerNotFound
+		return nil, nil, ErrPlayerNotFound
 	}
 
-	if err := room.Engine.Apply(playerID, action); err != nil {
+	events, err := applyPlayerActionLocked(room, playerID, action)
+	if err != nil {
+		return nil, nil, err
+	}
+	room.Version++
+	return snapshotRoom(room), events, nil
+}
+
+// LeaveUpdate is the outcome of a player leaving a room.
+type LeaveUpdate struct {
+	Room   *Room
+	Events []game.Event
+	// HostChanged is set when the leaving host's role passed to another
+	// player.
+	HostChanged bool
+	// Closed is set when the last human left a waiting room, which is
+	// then removed.
+	Closed bool
+}
+
+// LeaveRoom takes a player out of a room. Before the game starts the seat is
+// freed; during the game the player resigns. A leaving host hands the room to
+// the next human still playing.
+func (s *Store) LeaveRoom(roomRef, playerID string) (LeaveUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return LeaveUpdate{}, ErrRoomNotFound
+	}
+	if room.Status == RoomWaiting {
+		return s.leaveWaitingRoomLocked(room, strings.TrimSpace(playerID))
+	}
+	if room.Status == RoomQuarantined {
+		return LeaveUpdate{}, ErrRoomQuarantined
+	}
+	if !containsPlayer(room.Players, playerID) {
+		return LeaveUpdate{}, ErrPlayerNotFound
+	}
+
+	events, err := applyPlayerActionLocked(room, playerID, game.Action{Type: "resign"})
+	if err != nil {
+		return LeaveUpdate{}, err
+	}
+	room.Engine.SetConnected(playerID, false)
+	hostChanged := room.HostID == playerID && passHostLocked(room)
+	room.Version++
+	return LeaveUpdate{Room: snapshotRoom(room), Events: events, HostChanged: hostChanged}, nil
+}
+
+func (s *Store) leaveWaitingRoomLocked(room *roomEntity, playerID string) (LeaveUpdate, error) {
+	if !containsPlayer(room.Players, playerID) {
+		return LeaveUpdate{}, ErrPlayerNotFound
+	}
+	hostChanged := room.HostID == playerID && passHostLocked(room)
+	removePlayerLocked(room, playerID)
+	room.Version++
+	if room.HostID == playerID {
+		s.closeRoomLocked(room)
+		return LeaveUpdate{Room: snapshotRoom(room), Closed: true}, nil
+	}
+	return LeaveUpdate{Room: snapshotRoom(room), HostChanged: hostChanged}, nil
+}
+
+// applyPlayerActionLocked plays a move a player made themselves, then any bot
+// moves it set off.
+func applyPlayerActionLocked(room *roomEntity, playerID string, action game.Action) ([]game.Event, error) {
+	// A resignation out of turn leaves the current player's timer running.
+	onTurn := room.Engine.CurrentPlayerID() == playerID
+	events, err := room.Engine.Apply(playerID, action)
+	if err != nil {
+		if errors.Is(err, game.ErrInvariantViolation) {
+			quarantineLocked(room, err)
+		}
 		return nil, err
 	}
 
-	snapshot := room.Engine.Snapshot()
-	if snapshot.Status == game.StatusFinished && room.Status != RoomFinished {
-		now := time.Now().UTC()
-		room.Status = RoomFinished
-		room.FinishedAt = &now
-		room.TurnDeadline = nil
-	} else {
-		now := time.Now().UTC()
-		room.TurnDeadline = ptrTime(now.Add(time.Duration(room.TurnSeconds) * time.Second))
+	delete(room.Timeouts, playerID)
+	now := time.Now().UTC()
+	logMoveLocked(room, playerID, events, false, now)
+	if onTurn || room.Engine.CurrentPlayerID() == "" {
+		advanceTurnLocked(room, now)
 	}
+	ended, err := endBotOnlyGameLocked(room, now)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, ended...)
+	return append(events, playBotsLocked(room, now)...), nil
+}
 
-	return snapshotRoom(room), nil
+// LegalActions lists the moves the game would accept from playerID right now.
+func (s *Store) LegalActions(roomRef, playerID string) ([]game.Action, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return nil, ErrRoomNotFound
+	}
+	if room.Engine == nil {
+		return nil, ErrGameNotStarted
+	}
+	if !containsPlayer(room.Players, playerID) {
+		return nil, ErrPlayerNotFound
+	}
+	return room.Engine.LegalActions(playerID), nil
+}
+
+// PreviewPurchase reports what buying a card would cost playerID.
+func (s *Store) PreviewPurchase(roomRef, playerID, cardID, source string) (game.PurchasePreview, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return game.PurchasePreview{}, ErrRoomNotFound
+	}
+	if room.Engine == nil {
+		return game.PurchasePreview{}, ErrGameNotStarted
+	}
+	if !containsPlayer(room.Players, playerID) {
+		return game.PurchasePreview{}, ErrPlayerNotFound
+	}
+	return room.Engine.PreviewPurchase(playerID, cardID, source)
 }
 
 func (s *Store) ProcessTimeouts(now time.Time) []TimeoutUpdate {
@@ -236,20 +680,45 @@ func (s *Store) ProcessTimeouts(now time.Time) []TimeoutUpdate {
 		if currentPlayerID == "" {
 			continue
 		}
-		if err := room.Engine.Apply(currentPlayerID, game.Action{Type: "pass"}); err != nil {
+		// A searching bot is still thinking outside the lock, and
+		// ApplyBotMove restarts its timer.
+		if policy := room.Bots[currentPlayerID]; policy != nil && searching(policy) {
 			continue
 		}
-
-		updated := room.Engine.Snapshot()
-		if updated.Status == game.StatusFinished {
-			room.Status = RoomFinished
-			room.FinishedAt = &now
-			room.TurnDeadline = nil
-		} else {
-			room.TurnDeadline = ptrTime(now.Add(time.Duration(room.TurnSeconds) * time.Second))
+		// A bot still on turn here was cut off by maxBotMoves; let it
+		// carry on rather than acting for it.
+		reason := ReasonTurnTimeout
+		var events []game.Event
+		if room.Bots[currentPlayerID] == nil {
+			var err error
+			reason, events, err = applyTimeoutPolicyLocked(room, currentPlayerID)
+			if err != nil {
+				if errors.Is(err, game.ErrInvariantViolation) {
+					quarantineLocked(room, err)
+					updates = append(updates, TimeoutUpdate{Room: snapshotRoom(room), Reason: reason})
+				}
+				continue
+			}
+			if reason == ReasonSeatForfeits {
+				name := room.playerName(currentPlayerID)
+				room.appendLog(now, "seat_forfeited", map[string]any{"player": name},
+					fmt.Sprintf("%s timed out too often; a bot takes over the seat", name))
+				// A failed end quarantines the room or leaves it playing
+				// on; either way there is nothing more to add.
+				if ended, err := endBotOnlyGameLocked(room, now); err == nil {
+					events = append(events, ended...)
+				}
+			} else {
+				logMoveLocked(room, currentPlayerID, events, true, now)
+			}
+			if room.Bots[currentPlayerID] == nil {
+				advanceTurnLocked(room, now)
+			}
 		}
+		events = append(events, playBotsLocked(room, now)...)
+		room.Version++
 
-		updates = append(updates, TimeoutUpdate{Room: snapshotRoom(room)})
+		updates = append(updates, TimeoutUpdate{Room: snapshotRoom(room), Reason: reason, Events: events})
 	}
 
 	return updates
@@ -268,6 +737,7 @@ func (s *Store) SetConnected(roomRef, playerID string, connected bool) error {
 	}
 
 	room.Engine.SetConnected(playerID, connected)
+	room.Version++
 	return nil
 }
 
@@ -297,6 +767,163 @@ func (s *Store) resolveRoomLocked(roomRef string) (*roomEntity, bool) {
 	return nil, false
 }
 
+// applyTimeoutPolicyLocked acts for a human player whose turn timer ran out
+// and reports what it did. A seat forfeited to a bot is left on turn for
+// playBotsLocked to move.
+func applyTimeoutPolicyLocked(room *roomEntity, playerID string) (string, []game.Event, error) {
+	if room.Timeouts == nil {
+		room.Timeouts = make(map[string]int)
+	}
+	room.Timeouts[playerID]++
+
+	switch room.Timeout.Mode {
+	case TimeoutBot:
+		policy, err := bot.New(bot.DifficultyNormal, time.Now().UnixNano())
+		if err != nil {
+			return ReasonTimeoutBot, nil, err
+		}
+		state := room.Engine.Snapshot()
+		events, err := room.Engine.ApplyTimeoutAction(playerID, policy.Choose(state.RedactedFor(playerID), playerID))
+		if err != nil && !errors.Is(err, game.ErrInvariantViolation) {
+			events, err = room.Engine.ApplyTimeout(playerID)
+		}
+		// A move that leaves a discard or noble choice pending is settled
+		// within this timeout rather than keeping the absent player on turn.
+		for err == nil {
+			pending := room.Engine.Snapshot().Pending
+			if pending == nil || pending.PlayerID != playerID {
+				break
+			}
+			var more []game.Event
+			more, err = room.Engine.ApplyTimeout(playerID)
+			events = append(events, more...)
+		}
+		return ReasonTimeoutBot, events, err
+	case TimeoutForfeit:
+		if room.Timeouts[playerID] >= room.Timeout.ForfeitAfter {
+			return ReasonSeatForfeits, nil, forfeitSeatLocked(room, playerID)
+		}
+	}
+	events, err := room.Engine.ApplyTimeout(playerID)
+	return ReasonTurnTimeout, events, err
+}
+
+// forfeitSeatLocked hands a human seat to a normal bot for the rest of the
+// game.
+func forfeitSeatLocked(room *roomEntity, playerID string) error {
+	policy, err := bot.New(bot.DifficultyNormal, time.Now().UnixNano())
+	if err != nil {
+		return err
+	}
+	for i := range room.Players {
+		if room.Players[i].ID == playerID {
+			room.Players[i].Bot = true
+			room.Players[i].Difficulty = bot.DifficultyNormal
+			room.Players[i].Forfeited = true
+		}
+	}
+	if room.Bots == nil {
+		room.Bots = make(map[string]bot.Policy)
+	}
+	room.Bots[playerID] = policy
+	delete(room.Timeouts, playerID)
+	return nil
+}
+
+// advanceTurnLocked updates the room after an accepted move: it finishes the
+// room when the game is over and restarts the turn timer otherwise.
+func advanceTurnLocked(room *roomEntity, now time.Time) {
+	if room.Engine.Snapshot().Status == game.StatusFinished {
+		if room.Status != RoomFinished {
+			room.Status = RoomFinished
+			room.FinishedAt = &now
+		}
+		room.TurnDeadline = nil
+		return
+	}
+	room.TurnDeadline = ptrTime(now.Add(time.Duration(room.TurnSeconds) * time.Second))
+}
+
+// endBotOnlyGameLocked ends the game once no human seat is left in it, so
+// bots do not play on for nobody. Humans who resigned or forfeited their seat
+// no longer count; disconnected ones still do, since they may come back.
+func endBotOnlyGameLocked(room *roomEntity, now time.Time) ([]game.Event, error) {
+	if room.Status != RoomPlaying {
+		return nil, nil
+	}
+	state := room.Engine.Snapshot()
+	for _, p := range state.Players {
+		if !p.Resigned && room.Bots[p.ID] == nil {
+			return nil, nil
+		}
+	}
+
+	events, err := room.Engine.End()
+	if err != nil {
+		if errors.Is(err, game.ErrInvariantViolation) {
+			quarantineLocked(room, err)
+		}
+		return nil, err
+	}
+	room.appendLog(now, "game_abandoned", map[string]any{}, "No human players are left; the game ends here")
+	for _, ev := range events {
+		if ev.Type == game.EventGameFinished {
+			logGameFinishedLocked(room, ev, now)
+		}
+	}
+	advanceTurnLocked(room, now)
+	return events, nil
+}
+
+// playBotsLocked plays bot seats for as long as one is on turn, stopping at
+// a searching bot (see BotTurn). Bots only see what their own seat may see.
+// It returns the events of the moves played.
+func playBotsLocked(room *roomEntity, now time.Time) []game.Event {
+	var all []game.Event
+	for i := 0; i < maxBotMoves && room.Status == RoomPlaying; i++ {
+		state := room.Engine.Snapshot()
+		botID := state.CurrentPlayerID
+		policy := room.Bots[botID]
+		if policy == nil || searching(policy) {
+			break
+		}
+
+		events, err := playBotMoveLocked(room, botID, policy.Choose(state.RedactedFor(botID), botID), now)
+		if err != nil {
+			break
+		}
+		all = append(all, events...)
+	}
+	return all
+}
+
+// playBotMoveLocked plays one bot move. A move the engine rejects falls back
+// to the timeout move so a faulty policy cannot stall the room.
+func playBotMoveLocked(room *roomEntity, botID string, action game.Action, now time.Time) ([]game.Event, error) {
+	events, err := room.Engine.Apply(botID, action)
+	if err != nil && !errors.Is(err, game.ErrInvariantViolation) {
+		events, err = room.Engine.ApplyTimeout(botID)
+	}
+	if err != nil {
+		if errors.Is(err, game.ErrInvariantViolation) {
+			quarantineLocked(room, err)
+		}
+		return nil, err
+	}
+	logMoveLocked(room, botID, events, false, now)
+	advanceTurnLocked(room, now)
+	return events, nil
+}
+
+// quarantineLocked stops a room whose game state failed its invariant check,
+// keeping the error for inspection instead of playing on with corrupted state.
+func quarantineLocked(room *roomEntity, err error) {
+	room.Status = RoomQuarantined
+	room.Version++
+	room.Error = err.Error()
+	room.TurnDeadline = nil
+}
+
 func containsPlayer(players []Player, playerID string) bool {
 	for _, p := range players {
 		if p.ID == playerID {
@@ -308,24 +935,58 @@ func containsPlayer(players []Player, playerID string) bool {
 
 func snapshotRoom(room *roomEntity) *Room {
 	out := &Room{
-		ID:           room.ID,
-		Code:         room.Code,
-		HostID:       room.HostID,
-		Status:       room.Status,
-		TurnSeconds:  room.TurnSeconds,
-		TurnDeadline: room.TurnDeadline,
-		Players:      append([]Player(nil), room.Players...),
-		CreatedAt:    room.CreatedAt,
-		StartedAt:    room.StartedAt,
-		FinishedAt:   room.FinishedAt,
+		ID:            room.ID,
+		Code:          room.Code,
+		HostID:        room.HostID,
+		Status:        room.Status,
+		TurnSeconds:   room.TurnSeconds,
+		TurnDeadline:  room.TurnDeadline,
+		Rules:         room.Rules,
+		Mode:          room.Mode,
+		TimeoutPolicy: room.Timeout,
+		MaxPlayers:    room.MaxPlayers,
+		Visibility:    room.Visibility,
+		HasPassword:   room.Password.set(),
+		AutoStart:     room.AutoStart,
+		Players:       append([]Player(nil), room.Players...),
+		CreatedAt:     room.CreatedAt,
+		StartedAt:     room.StartedAt,
+		FinishedAt:    room.FinishedAt,
+		Error:         room.Error,
+		LogLimit:      room.LogLimit,
+		Log:           append(make([]LogLine, 0, len(room.Log)), room.Log...),
+		Version:       room.Version,
 	}
 	if room.Engine != nil {
 		s := room.Engine.Snapshot()
 		out.Game = &s
+		// The seed reveals the deck order, so it is only published once the
+		// game can no longer be affected by it.
+		if room.Status == RoomFinished {
+			seed := room.Engine.Seed()
+			out.Seed = &seed
+		}
 	}
 	return out
 }
 
+// botName picks the first "Bot N" name not yet taken in the room.
+func botName(players []Player) string {
+	for n := 1; ; n++ {
+		name := fmt.Sprintf("Bot %d", n)
+		taken := false
+		for _, p := range players {
+			if strings.EqualFold(p.Name, name) {
+				taken = true
+				break
+			}
+		}
+		if !taken {
+			return name
+		}
+	}
+}
+
 func ptrTime(t time.Time) *time.Time {
 	v := t.UTC()
 	return &v
@@ -1,6 +1,7 @@
 package lobby
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -29,7 +30,8 @@ func TestCreateJoinStartAndAction(t *testing.T) {
 		t.Fatalf("expected 2 players, got %d", len(joinedRoom.Players))
 	}
 
-	startedRoom, err := store.StartGame(room.ID, room.HostID)
+	readyAll(t, store, room.ID)
+	startedRoom, err := store.StartGame(room.ID, room.HostID, StartOptions{})
 	if err != nil {
 		t.Fatalf("start game failed: %v", err)
 	}
@@ -41,7 +43,7 @@ func TestCreateJoinStartAndAction(t *testing.T) {
 	}
 
 	action := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"white", "blue", "green"}}}
-	actionedRoom, err := store.ApplyAction(room.ID, room.HostID, action)
+	actionedRoom, _, err := store.ApplyAction(room.ID, room.HostID, action)
 	if err != nil {
 		t.Fatalf("apply action failed: %v", err)
 	}
@@ -61,7 +63,7 @@ func TestOnlyHostCanStart(t *testing.T) {
 		t.Fatalf("join failed: %v", err)
 	}
 
-	_, err = store.StartGame(room.ID, player.ID)
+	_, err = store.StartGame(room.ID, player.ID, StartOptions{})
 	if err == nil {
 		t.Fatal("expected only host can start error")
 	}
@@ -81,7 +83,8 @@ func TestProcessTimeoutsAutoPass(t *testing.T) {
 		t.Fatalf("join room failed: %v", err)
 	}
 
-	started, err := store.StartGame(room.ID, room.HostID)
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
 	if err != nil {
 		t.Fatalf("start game failed: %v", err)
 	}
@@ -104,3 +107,889 @@ func TestProcessTimeoutsAutoPass(t *testing.T) {
 		t.Fatal("expected timeout to pass turn to next player")
 	}
 }
+
+func TestSeedPublishedOnlyAfterFinish(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoom("host", 0)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "friend"); err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+
+	seed := int64(7)
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{Seed: &seed})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	if started.Seed != nil {
+		t.Fatal("expected seed to stay hidden while playing")
+	}
+
+	store.rooms[room.ID].Status = RoomFinished
+	finished, err := store.GetRoom(room.ID)
+	if err != nil {
+		t.Fatalf("get room failed: %v", err)
+	}
+	if finished.Seed == nil || *finished.Seed != seed {
+		t.Fatalf("expected finished room to expose seed %d, got %v", seed, finished.Seed)
+	}
+}
+
+func TestCreateRoomWithRules(t *testing.T) {
+	store := NewStore()
+
+	if _, err := store.CreateRoomWithOptions("host", RoomOptions{Rules: game.RuleSet{TargetScore: 15}}); !errors.Is(err, game.ErrInvalidRules) {
+		t.Fatalf("expected invalid rules error, got %v", err)
+	}
+
+	rules, err := game.PresetRules(game.PresetQuick)
+	if err != nil {
+		t.Fatalf("preset failed: %v", err)
+	}
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Rules: rules})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if room.Rules != rules {
+		t.Fatalf("expected room rules %+v, got %+v", rules, room.Rules)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "friend"); err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	if started.Game.Rules != rules {
+		t.Fatalf("expected game to use room rules, got %+v", started.Game.Rules)
+	}
+
+	plain, err := store.CreateRoom("other", 0)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if plain.Rules != game.DefaultRules() {
+		t.Fatalf("expected default rules, got %+v", plain.Rules)
+	}
+}
+
+func TestStrictRoomMode(t *testing.T) {
+	store := NewStore()
+	if _, err := store.CreateRoomWithOptions("host", RoomOptions{Mode: "chaos"}); !errors.Is(err, game.ErrInvalidMode) {
+		t.Fatalf("expected invalid mode error, got %v", err)
+	}
+
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Mode: game.ModeStrict})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if room.Mode != game.ModeStrict {
+		t.Fatalf("expected strict room, got %s", room.Mode)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "friend"); err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+	readyAll(t, store, room.ID)
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	_, _, err = store.ApplyAction(room.ID, room.HostID, game.Action{Type: "adjust_tokens", Payload: game.ActionInput{Adjust: map[string]int{"red": 2}}})
+	if !errors.Is(err, game.ErrInvalidAction) {
+		t.Fatalf("expected adjust_tokens to be rejected in strict room, got %v", err)
+	}
+
+	sandbox, err := store.CreateRoom("other", 0)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if sandbox.Mode != game.ModeSandbox {
+		t.Fatalf("expected sandbox by default, got %s", sandbox.Mode)
+	}
+}
+
+func TestQuarantinedRoomRejectsActions(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Mode: game.ModeStrict})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "friend"); err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	quarantineLocked(store.rooms[room.ID], game.ErrInvariantViolation)
+	quarantined, err := store.GetRoom(room.ID)
+	if err != nil {
+		t.Fatalf("get room failed: %v", err)
+	}
+	if quarantined.Status != RoomQuarantined || quarantined.Error == "" || quarantined.TurnDeadline != nil {
+		t.Fatalf("unexpected quarantined room: %+v", quarantined)
+	}
+
+	_, _, err = store.ApplyAction(room.ID, started.Game.CurrentPlayerID, game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"white"}}})
+	if !errors.Is(err, ErrRoomQuarantined) {
+		t.Fatalf("expected ErrRoomQuarantined, got %v", err)
+	}
+	if updates := store.ProcessTimeouts(time.Now().Add(time.Hour)); len(updates) != 0 {
+		t.Fatalf("expected quarantined room to be skipped by timeouts, got %d updates", len(updates))
+	}
+}
+
+func TestBotSeatPlaysItsTurns(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Mode: game.ModeStrict})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, friend, err := store.JoinRoom(room.ID, "friend")
+	if err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+	if _, _, err := store.AddBot(room.ID, friend.ID, ""); !errors.Is(err, ErrOnlyHost) {
+		t.Fatalf("expected ErrOnlyHost, got %v", err)
+	}
+	withBot, botPlayer, err := store.AddBot(room.ID, room.HostID, "easy")
+	if err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+	if !botPlayer.Bot || botPlayer.Difficulty != "easy" || botPlayer.Name != "Bot 1" || len(withBot.Players) != 3 {
+		t.Fatalf("unexpected bot seat: %+v", botPlayer)
+	}
+
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	if started.Game.CurrentPlayerID != room.HostID {
+		t.Fatalf("expected host on turn, got %s", started.Game.CurrentPlayerID)
+	}
+
+	take := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"white"}}}
+	if _, _, err := store.ApplyAction(room.ID, room.HostID, take); err != nil {
+		t.Fatalf("host action failed: %v", err)
+	}
+	afterFriend, _, err := store.ApplyAction(room.ID, friend.ID, take)
+	if err != nil {
+		t.Fatalf("friend action failed: %v", err)
+	}
+	if afterFriend.Game.CurrentPlayerID != room.HostID || afterFriend.Game.Turn != 4 {
+		t.Fatalf("expected bot to move and hand the turn back, got turn %d for %s", afterFriend.Game.Turn, afterFriend.Game.CurrentPlayerID)
+	}
+
+	// The bot also moves after a human's timeout.
+	if _, _, err := store.ApplyAction(room.ID, room.HostID, take); err != nil {
+		t.Fatalf("host action failed: %v", err)
+	}
+	current, _ := store.GetRoom(room.ID)
+	updates := store.ProcessTimeouts(current.TurnDeadline.Add(time.Second))
+	if len(updates) != 1 || updates[0].Room.Game.CurrentPlayerID != room.HostID {
+		t.Fatalf("expected timeout and bot move to return the turn to host, got %+v", updates)
+	}
+}
+
+func TestSearchingBotMovesOutsideTheLock(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Mode: game.ModeStrict})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if _, _, err := store.AddBot(room.ID, room.HostID, "hard"); err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+	if _, ok := store.BotTurn(room.ID); ok {
+		t.Fatal("expected no bot turn before the game starts")
+	}
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	take := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"white"}}}
+	after, _, err := store.ApplyAction(room.ID, room.HostID, take)
+	if err != nil {
+		t.Fatalf("host action failed: %v", err)
+	}
+	if after.Game.CurrentPlayerID == room.HostID {
+		t.Fatal("expected the hard bot to be left on turn")
+	}
+	if updates := store.ProcessTimeouts(after.TurnDeadline.Add(time.Second)); len(updates) != 0 {
+		t.Fatalf("expected a thinking bot's timer to be left alone, got %+v", updates)
+	}
+
+	stale, ok := store.BotTurn(room.ID)
+	if !ok || stale.PlayerID != after.Game.CurrentPlayerID {
+		t.Fatalf("expected a turn for the bot, got %+v", stale)
+	}
+	if err := store.SetConnected(room.ID, room.HostID, false); err != nil {
+		t.Fatalf("set connected failed: %v", err)
+	}
+	if _, _, err := store.ApplyBotMove(stale, stale.Choose()); !errors.Is(err, ErrStaleBotTurn) {
+		t.Fatalf("expected ErrStaleBotTurn, got %v", err)
+	}
+
+	turn, _ := store.BotTurn(room.ID)
+	moved, events, err := store.ApplyBotMove(turn, turn.Choose())
+	if err != nil {
+		t.Fatalf("bot move failed: %v", err)
+	}
+	if moved.Game.CurrentPlayerID != room.HostID || len(events) == 0 {
+		t.Fatalf("expected the bot to hand the turn back, got %s", moved.Game.CurrentPlayerID)
+	}
+}
+
+func TestTimeoutPolicies(t *testing.T) {
+	if _, err := NewStore().CreateRoomWithOptions("host", RoomOptions{TimeoutPolicy: TimeoutPolicy{Mode: "kick"}}); !errors.Is(err, ErrInvalidTimeout) {
+		t.Fatalf("expected ErrInvalidTimeout, got %v", err)
+	}
+
+	start := func(policy TimeoutPolicy) (*Store, *Room) {
+		store := NewStore()
+		room, err := store.CreateRoomWithOptions("host", RoomOptions{TurnSeconds: 5, TimeoutPolicy: policy})
+		if err != nil {
+			t.Fatalf("create room failed: %v", err)
+		}
+		if _, _, err := store.JoinRoom(room.ID, "friend"); err != nil {
+			t.Fatalf("join room failed: %v", err)
+		}
+		readyAll(t, store, room.ID)
+		started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+		if err != nil {
+			t.Fatalf("start game failed: %v", err)
+		}
+		return store, started
+	}
+	expire := func(store *Store, roomID string) TimeoutUpdate {
+		room, _ := store.GetRoom(roomID)
+		updates := store.ProcessTimeouts(room.TurnDeadline.Add(time.Second))
+		if len(updates) != 1 {
+			t.Fatalf("expected 1 timeout update, got %d", len(updates))
+		}
+		return updates[0]
+	}
+
+	store, room := start(TimeoutPolicy{Mode: TimeoutBot})
+	if room.TimeoutPolicy.Mode != TimeoutBot {
+		t.Fatalf("expected policy in snapshot, got %+v", room.TimeoutPolicy)
+	}
+	update := expire(store, room.ID)
+	if update.Reason != ReasonTimeoutBot {
+		t.Fatalf("expected reason %s, got %s", ReasonTimeoutBot, update.Reason)
+	}
+	if move := update.Room.Game.Players[0].LastAction; move == "pass" || move == "" {
+		t.Fatalf("expected the bot to play a real move, got %q", move)
+	}
+
+	// With three tokens at a limit of three, every move the bot has takes the
+	// host over the limit; the discard is settled in the same timeout.
+	limit := game.DefaultRules()
+	limit.Preset, limit.TokenLimit = game.PresetCustom, 3
+	store = NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{TurnSeconds: 5, Rules: limit, Mode: game.ModeStrict, TimeoutPolicy: TimeoutPolicy{Mode: TimeoutBot}})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, friend, _ := store.JoinRoom(room.ID, "friend")
+	readyAll(t, store, room.ID)
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	for _, move := range []struct {
+		playerID string
+		colors   []string
+	}{{room.HostID, []string{"white", "blue", "green"}}, {friend.ID, []string{"red"}}} {
+		if _, _, err := store.ApplyAction(room.ID, move.playerID, game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: move.colors}}); err != nil {
+			t.Fatalf("take failed: %v", err)
+		}
+	}
+	update = expire(store, room.ID)
+	g := update.Room.Game
+	if g.Pending != nil || g.CurrentPlayerID != friend.ID || g.Players[0].Tokens.Total() > 3 {
+		t.Fatalf("expected the discard settled and the turn passed, got pending %+v on %s", g.Pending, g.CurrentPlayerID)
+	}
+	if n := store.rooms[room.ID].Timeouts[room.HostID]; n != 1 {
+		t.Fatalf("expected one timeout counted, got %d", n)
+	}
+
+	store, room = start(TimeoutPolicy{Mode: TimeoutForfeit, ForfeitAfter: 2})
+	hostID := room.HostID
+	friendID := room.Players[1].ID
+	if update := expire(store, room.ID); update.Reason != ReasonTurnTimeout {
+		t.Fatalf("expected first timeout to pass, got %s", update.Reason)
+	}
+	take := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"white"}}}
+	if _, _, err := store.ApplyAction(room.ID, friendID, take); err != nil {
+		t.Fatalf("friend action failed: %v", err)
+	}
+	update = expire(store, room.ID)
+	if update.Reason != ReasonSeatForfeits {
+		t.Fatalf("expected second consecutive timeout to forfeit, got %s", update.Reason)
+	}
+	host := update.Room.Players[0]
+	if !host.Bot || !host.Forfeited {
+		t.Fatalf("expected host seat handed to a bot, got %+v", host)
+	}
+	if update.Room.Game.CurrentPlayerID != friendID || update.Room.Game.Players[0].ID != hostID {
+		t.Fatalf("expected the bot to play the forfeited turn")
+	}
+}
+
+func TestGameLog(t *testing.T) {
+	store := NewStore()
+	if _, err := store.CreateRoomWithOptions("host", RoomOptions{LogLimit: 1000}); !errors.Is(err, ErrInvalidLogLimit) {
+		t.Fatalf("expected ErrInvalidLogLimit, got %v", err)
+	}
+	room, err := store.CreateRoomWithOptions("Alice", RoomOptions{LogLimit: 4})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, bob, err := store.JoinRoom(room.ID, "Bob")
+	if err != nil {
+		t.Fatalf("join room failed: %v", err)
+	}
+	readyAll(t, store, room.ID)
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	take := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"green", "white", "blue"}}}
+	if _, _, err := store.ApplyAction(room.ID, room.HostID, take); err != nil {
+		t.Fatalf("host action failed: %v", err)
+	}
+	reserve := game.Action{Type: "reserve_card", Payload: game.ActionInput{Source: "deck", Tier: 1}}
+	if _, _, err := store.ApplyAction(room.ID, bob.ID, reserve); err != nil {
+		t.Fatalf("bob action failed: %v", err)
+	}
+	current, _ := store.GetRoom(room.ID)
+	store.ProcessTimeouts(current.TurnDeadline.Add(time.Second))
+
+	current, _ = store.GetRoom(room.ID)
+	if current.LogLimit != 4 || len(current.Log) != 4 {
+		t.Fatalf("expected 4 log lines, got %+v", current.Log)
+	}
+	want := []struct{ key, text string }{
+		{"game_started", "Game started; Alice goes first"},
+		{"took_tokens", "Alice took white, blue, green"},
+		{"reserved_card_blind", "Bob reserved a tier 1 card from the deck and took 1 gold"},
+		{"passed", "Alice passed (timeout)"},
+	}
+	for i, line := range current.Log {
+		if line.Seq != i+1 || line.Key != want[i].key || line.Text != want[i].text {
+			t.Fatalf("line %d: got %+v, want %+v", i, line, want[i])
+		}
+	}
+	if _, ok := current.Log[2].Params["cardId"]; ok {
+		t.Fatalf("blind reserve must not reveal the card: %+v", current.Log[2].Params)
+	}
+
+	// A fifth line pushes the oldest one out.
+	if _, _, err := store.ApplyAction(room.ID, bob.ID, game.Action{Type: "pass"}); err != nil {
+		t.Fatalf("bob pass failed: %v", err)
+	}
+	page, err := store.GameLog(room.ID, 2, 2)
+	if err != nil {
+		t.Fatalf("game log failed: %v", err)
+	}
+	if page.OldestSeq != 2 || !page.HasMore || len(page.Lines) != 2 || page.Lines[0].Seq != 3 || page.Lines[1].Seq != 4 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+	page, _ = store.GameLog(room.ID, 4, 0)
+	if page.HasMore || len(page.Lines) != 1 || page.Lines[0].Text != "Bob passed" {
+		t.Fatalf("unexpected last page: %+v", page)
+	}
+	if _, err := store.GameLog("missing", 0, 0); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("expected ErrRoomNotFound, got %v", err)
+	}
+}
+
+func TestGameLogLinesKeepOwnParams(t *testing.T) {
+	room := &roomEntity{LogLimit: DefaultLogLimit, Players: []Player{{ID: "p1", Name: "Alice"}}}
+	events := []game.Event{
+		{Type: game.EventTokensTaken, Tokens: &game.TokenSet{White: 1, Blue: 1}},
+		{Type: game.EventNobleClaimed, Noble: &game.Noble{ID: "n1"}},
+	}
+	logMoveLocked(room, "p1", events, false, time.Now())
+
+	if len(room.Log) != 2 || room.Log[0].Key != "took_tokens" || room.Log[1].Key != "claimed_noble" {
+		t.Fatalf("unexpected lines: %+v", room.Log)
+	}
+	if _, ok := room.Log[0].Params["nobleId"]; ok {
+		t.Fatalf("took_tokens picked up the noble: %+v", room.Log[0].Params)
+	}
+	if _, ok := room.Log[1].Params["colors"]; ok {
+		t.Fatalf("claimed_noble picked up the colors: %+v", room.Log[1].Params)
+	}
+}
+
+func TestLeaveRoomResigns(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoom("host", 30)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, a, _ := store.JoinRoom(room.ID, "a")
+	_, b, _ := store.JoinRoom(room.ID, "b")
+	if _, err := store.LeaveRoom(room.ID, "nobody"); !errors.Is(err, ErrPlayerNotFound) {
+		t.Fatalf("expected ErrPlayerNotFound, got %v", err)
+	}
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	update, err := store.LeaveRoom(room.ID, b.ID)
+	if err != nil {
+		t.Fatalf("leave failed: %v", err)
+	}
+	if len(update.Events) != 1 || update.Events[0].Type != game.EventPlayerResigned || update.HostChanged {
+		t.Fatalf("unexpected leave update: %+v", update)
+	}
+	left := update.Room
+	if left.Game.CurrentPlayerID != room.HostID || !left.TurnDeadline.Equal(*started.TurnDeadline) {
+		t.Fatal("expected an out-of-turn resignation to leave the turn alone")
+	}
+	if last := left.Log[len(left.Log)-1]; last.Key != "resigned" || last.Text != "b resigned" {
+		t.Fatalf("unexpected log line: %+v", last)
+	}
+
+	take := game.Action{Type: "take_tokens", Payload: game.ActionInput{Colors: []string{"red"}}}
+	for _, id := range []string{room.HostID, a.ID} {
+		if _, _, err := store.ApplyAction(room.ID, id, take); err != nil {
+			t.Fatalf("action failed: %v", err)
+		}
+	}
+	current, _ := store.GetRoom(room.ID)
+	if current.Game.CurrentPlayerID != room.HostID {
+		t.Fatalf("expected b to be skipped, got %s on turn", current.Game.CurrentPlayerID)
+	}
+	if _, err := store.TransferHost(room.ID, room.HostID, b.ID); !errors.Is(err, ErrInvalidTarget) {
+		t.Fatalf("expected host transfer to a resigned player to fail, got %v", err)
+	}
+
+	// The host leaving hands the room to a.
+	update, err = store.LeaveRoom(room.ID, room.HostID)
+	if err != nil {
+		t.Fatalf("leave failed: %v", err)
+	}
+	finished := update.Room
+	if !update.HostChanged || finished.HostID != a.ID {
+		t.Fatalf("expected host to pass to a, got %s", finished.HostID)
+	}
+	if finished.Status != RoomFinished || len(finished.Game.WinnerIDs) != 1 || finished.Game.WinnerIDs[0] != a.ID {
+		t.Fatalf("expected a to win once alone, got %s %v", finished.Status, finished.Game.WinnerIDs)
+	}
+	if _, err := store.LeaveRoom(room.ID, a.ID); !errors.Is(err, game.ErrGameFinished) {
+		t.Fatalf("expected ErrGameFinished, got %v", err)
+	}
+}
+
+func TestGameEndsWithoutHumans(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoom("host", 30)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, friend, _ := store.JoinRoom(room.ID, "friend")
+	for i := 0; i < 2; i++ {
+		if _, _, err := store.AddBot(room.ID, room.HostID, ""); err != nil {
+			t.Fatalf("add bot failed: %v", err)
+		}
+	}
+	readyAll(t, store, room.ID)
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+
+	update, err := store.LeaveRoom(room.ID, friend.ID)
+	if err != nil {
+		t.Fatalf("leave failed: %v", err)
+	}
+	if update.Room.Status != RoomPlaying {
+		t.Fatalf("expected the game to go on while the host plays, got %s", update.Room.Status)
+	}
+
+	// The last human leaving ends the game instead of leaving the bots to
+	// play on.
+	update, err = store.LeaveRoom(room.ID, room.HostID)
+	if err != nil {
+		t.Fatalf("leave failed: %v", err)
+	}
+	ended := update.Room
+	if ended.Status != RoomFinished || ended.Game.Status != game.StatusFinished || ended.TurnDeadline != nil {
+		t.Fatalf("expected the game to end, got room %s and game %s", ended.Status, ended.Game.Status)
+	}
+	if last := update.Events[len(update.Events)-1]; last.Type != game.EventGameFinished {
+		t.Fatalf("expected game_finished last, got %+v", update.Events)
+	}
+	keys := []string{ended.Log[len(ended.Log)-2].Key, ended.Log[len(ended.Log)-1].Key}
+	if keys[0] != "game_abandoned" || keys[1] != "game_finished" {
+		t.Fatalf("unexpected log lines: %v", keys)
+	}
+
+	// A forfeited seat counts as a bot too.
+	store = NewStore()
+	room, err = store.CreateRoomWithOptions("host", RoomOptions{TimeoutPolicy: TimeoutPolicy{Mode: TimeoutForfeit, ForfeitAfter: 1}})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, _, err := store.AddBot(room.ID, room.HostID, ""); err != nil {
+			t.Fatalf("add bot failed: %v", err)
+		}
+	}
+	started, err := store.StartGame(room.ID, room.HostID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	updates := store.ProcessTimeouts(started.TurnDeadline.Add(time.Second))
+	if len(updates) != 1 || updates[0].Reason != ReasonSeatForfeits || updates[0].Room.Status != RoomFinished {
+		t.Fatalf("expected the forfeit to end the game, got %+v", updates)
+	}
+}
+
+func TestHostControls(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoom("host", 30)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, a, _ := store.JoinRoom(room.ID, "a")
+	_, b, _ := store.JoinRoom(room.ID, "b")
+	_, botPlayer, err := store.AddBot(room.ID, room.HostID, "")
+	if err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+
+	if _, err := store.KickPlayer(room.ID, a.ID, b.ID); !errors.Is(err, ErrOnlyHost) {
+		t.Fatalf("expected ErrOnlyHost, got %v", err)
+	}
+	if _, err := store.KickPlayer(room.ID, room.HostID, room.HostID); !errors.Is(err, ErrInvalidTarget) {
+		t.Fatalf("expected ErrInvalidTarget, got %v", err)
+	}
+	if _, err := store.TransferHost(room.ID, room.HostID, botPlayer.ID); !errors.Is(err, ErrInvalidTarget) {
+		t.Fatalf("expected a bot to be refused as host, got %v", err)
+	}
+	for _, id := range []string{botPlayer.ID, b.ID} {
+		if _, err := store.KickPlayer(room.ID, room.HostID, id); err != nil {
+			t.Fatalf("kick failed: %v", err)
+		}
+	}
+	if _, b, err = store.JoinRoom(room.ID, "b"); err != nil {
+		t.Fatalf("expected a kicked name to be free again: %v", err)
+	}
+
+	if _, err := store.SetSeatOrder(room.ID, room.HostID, []string{a.ID, a.ID, b.ID}); !errors.Is(err, ErrInvalidSeatOrder) {
+		t.Fatalf("expected ErrInvalidSeatOrder, got %v", err)
+	}
+	ordered, err := store.SetSeatOrder(room.ID, room.HostID, []string{a.ID, room.HostID, b.ID})
+	if err != nil {
+		t.Fatalf("set seat order failed: %v", err)
+	}
+	if ordered.Players[0].ID != a.ID || ordered.Players[1].ID != room.HostID || ordered.Players[2].ID != b.ID {
+		t.Fatalf("unexpected seat order: %+v", ordered.Players)
+	}
+	shuffled, err := store.ShuffleSeats(room.ID, room.HostID)
+	if err != nil || len(shuffled.Players) != 3 {
+		t.Fatalf("shuffle failed: %v", err)
+	}
+	if _, err := store.SetSeatOrder(room.ID, room.HostID, []string{a.ID, room.HostID, b.ID}); err != nil {
+		t.Fatalf("set seat order failed: %v", err)
+	}
+
+	transferred, err := store.TransferHost(room.ID, room.HostID, a.ID)
+	if err != nil || transferred.HostID != a.ID {
+		t.Fatalf("transfer host failed: %v", err)
+	}
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); !errors.Is(err, ErrOnlyHostCanStart) {
+		t.Fatalf("expected the old host to lose the right to start, got %v", err)
+	}
+	readyAll(t, store, room.ID)
+	started, err := store.StartGame(room.ID, a.ID, StartOptions{})
+	if err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	if started.Game.CurrentPlayerID != a.ID {
+		t.Fatalf("expected the first seat to move first, got %s", started.Game.CurrentPlayerID)
+	}
+	if _, err := store.KickPlayer(room.ID, a.ID, b.ID); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Fatalf("expected ErrGameAlreadyStarted, got %v", err)
+	}
+	if _, err := store.CloseRoom(room.ID, a.ID); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Fatalf("expected ErrGameAlreadyStarted, got %v", err)
+	}
+
+	// Closing a waiting room deletes it even with other players seated.
+	other, _ := store.CreateRoom("host", 30)
+	store.JoinRoom(other.ID, "guest")
+	if _, err := store.CloseRoom(other.ID, "guest"); !errors.Is(err, ErrOnlyHost) {
+		t.Fatalf("expected ErrOnlyHost, got %v", err)
+	}
+	closed, err := store.CloseRoom(other.Code, other.HostID)
+	if err != nil || closed.Status != RoomClosed {
+		t.Fatalf("close failed: %+v %v", closed, err)
+	}
+	if _, err := store.GetRoom(other.ID); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("expected the closed room to be gone, got %v", err)
+	}
+}
+
+// readyAll marks every player but the host ready.
+func readyAll(t *testing.T, store *Store, roomID string) {
+	t.Helper()
+	room, err := store.GetRoom(roomID)
+	if err != nil {
+		t.Fatalf("get room failed: %v", err)
+	}
+	for _, p := range room.Players {
+		if p.ID == room.HostID {
+			continue
+		}
+		if _, err := store.SetReady(roomID, p.ID, true); err != nil {
+			t.Fatalf("ready failed: %v", err)
+		}
+	}
+}
+
+func TestReadyCheckAndWaitingRoomLeave(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{AutoStart: true})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	_, a, _ := store.JoinRoom(room.ID, "a")
+	_, b, _ := store.JoinRoom(room.ID, "b")
+
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{}); !errors.Is(err, ErrPlayersNotReady) {
+		t.Fatalf("expected ErrPlayersNotReady, got %v", err)
+	}
+
+	// b leaves, freeing the name and seat.
+	update, err := store.LeaveRoom(room.ID, b.ID)
+	if err != nil || update.Closed || update.HostChanged || len(update.Room.Players) != 2 {
+		t.Fatalf("unexpected leave: %+v %v", update, err)
+	}
+	if _, b, err = store.JoinRoom(room.ID, "b"); err != nil {
+		t.Fatalf("rejoin failed: %v", err)
+	}
+
+	// The host leaving hands the room to a.
+	update, err = store.LeaveRoom(room.ID, room.HostID)
+	if err != nil || !update.HostChanged || update.Room.HostID != a.ID {
+		t.Fatalf("expected host to pass to a: %+v %v", update, err)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "c"); err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+
+	// Filling the last seat with a bot starts the game once everyone is ready.
+	current, _ := store.GetRoom(room.ID)
+	for _, p := range current.Players {
+		if _, err := store.SetReady(room.ID, p.ID, true); err != nil {
+			t.Fatalf("ready failed: %v", err)
+		}
+	}
+	withBot, _, err := store.AddBot(room.ID, a.ID, "")
+	if err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+	if withBot.Status != RoomPlaying || withBot.Game == nil {
+		t.Fatalf("expected the full, ready room to start, got %s", withBot.Status)
+	}
+	if _, err := store.SetReady(room.ID, a.ID, false); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Fatalf("expected ErrGameAlreadyStarted, got %v", err)
+	}
+
+	// The host may force a start, and the last human leaving closes the room.
+	solo, _ := store.CreateRoom("solo", 30)
+	if _, _, err := store.AddBot(solo.ID, solo.HostID, ""); err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+	update, err = store.LeaveRoom(solo.ID, solo.HostID)
+	if err != nil || !update.Closed || update.Room.Status != RoomClosed {
+		t.Fatalf("expected the room to close: %+v %v", update, err)
+	}
+	if _, err := store.GetRoom(solo.ID); !errors.Is(err, ErrRoomNotFound) {
+		t.Fatalf("expected a closed room to be gone, got %v", err)
+	}
+
+	forced, _ := store.CreateRoom("host", 30)
+	if _, _, err := store.JoinRoom(forced.ID, "late"); err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	if started, err := store.StartGame(forced.ID, forced.HostID, StartOptions{Force: true}); err != nil || started.Status != RoomPlaying {
+		t.Fatalf("expected a forced start, got %v", err)
+	}
+}
+
+func TestUpdateSettings(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoom("host", 30)
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if room.MaxPlayers != MaxPlayers || room.Visibility != VisibilityPublic {
+		t.Fatalf("unexpected defaults: %d %s", room.MaxPlayers, room.Visibility)
+	}
+	_, a, _ := store.JoinRoom(room.ID, "a")
+	_, b, _ := store.JoinRoom(room.ID, "b")
+	readyAll(t, store, room.ID)
+
+	two, fast, private := 2, 10, "private"
+	if _, err := store.UpdateSettings(room.ID, a.ID, SettingsUpdate{TurnSeconds: &fast}); !errors.Is(err, ErrOnlyHost) {
+		t.Fatalf("expected ErrOnlyHost, got %v", err)
+	}
+	if _, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{MaxPlayers: &two}); !errors.Is(err, ErrInvalidMaxPlayers) {
+		t.Fatalf("expected fewer seats than players to fail, got %v", err)
+	}
+	tooSlow := 301
+	// A bad field leaves the others untouched.
+	if _, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{Visibility: &private, TurnSeconds: &tooSlow}); !errors.Is(err, ErrInvalidTurnSeconds) {
+		t.Fatalf("expected ErrInvalidTurnSeconds, got %v", err)
+	}
+	if _, err := store.KickPlayer(room.ID, room.HostID, b.ID); err != nil {
+		t.Fatalf("kick failed: %v", err)
+	}
+
+	quick, _ := game.ResolveRules(game.PresetQuick, game.RuleOverrides{})
+	policy := TimeoutPolicy{Mode: TimeoutBot}
+	updated, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{
+		TurnSeconds:   &fast,
+		Rules:         &quick,
+		MaxPlayers:    &two,
+		Visibility:    &private,
+		TimeoutPolicy: &policy,
+	})
+	if err != nil {
+		t.Fatalf("update settings failed: %v", err)
+	}
+	if updated.TurnSeconds != 10 || updated.Rules.Preset != game.PresetQuick || updated.MaxPlayers != 2 ||
+		updated.Visibility != VisibilityPrivate || updated.TimeoutPolicy.Mode != TimeoutBot {
+		t.Fatalf("unexpected settings: %+v", updated)
+	}
+	if updated.Players[1].Ready {
+		t.Fatal("expected a settings change to clear ready flags")
+	}
+	if _, _, err := store.JoinRoom(room.ID, "c"); !errors.Is(err, ErrRoomFull) {
+		t.Fatalf("expected ErrRoomFull, got %v", err)
+	}
+
+	gold, tooMuchGold := 3, -1
+	updated, err = store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{RuleOverrides: &game.RuleOverrides{GoldCount: &gold}})
+	if err != nil {
+		t.Fatalf("rule overrides failed: %v", err)
+	}
+	if updated.Rules.Preset != game.PresetCustom || updated.Rules.TargetScore != quick.TargetScore || updated.Rules.GoldCount != 3 {
+		t.Fatalf("expected quick rules with 3 gold, got %+v", updated.Rules)
+	}
+	if _, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{RuleOverrides: &game.RuleOverrides{GoldCount: &tooMuchGold}}); err == nil {
+		t.Fatal("expected invalid overrides to fail")
+	}
+
+	if _, err := store.StartGame(room.ID, room.HostID, StartOptions{Force: true}); err != nil {
+		t.Fatalf("start game failed: %v", err)
+	}
+	if _, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{TurnSeconds: &fast}); !errors.Is(err, ErrGameAlreadyStarted) {
+		t.Fatalf("expected ErrGameAlreadyStarted, got %v", err)
+	}
+
+	// Lowering the seat count of a ready AutoStart room to its players
+	// starts the game; the host does not have to mark themselves ready.
+	auto, _ := store.CreateRoomWithOptions("host", RoomOptions{AutoStart: true})
+	if _, _, err := store.AddBot(auto.ID, auto.HostID, ""); err != nil {
+		t.Fatalf("add bot failed: %v", err)
+	}
+	started, err := store.UpdateSettings(auto.ID, auto.HostID, SettingsUpdate{MaxPlayers: &two})
+	if err != nil || started.Status != RoomPlaying || started.Game == nil {
+		t.Fatalf("expected the full, ready room to start: %+v %v", started, err)
+	}
+}
+
+func TestPasswordProtectedRoom(t *testing.T) {
+	store := NewStore()
+	room, err := store.CreateRoomWithOptions("host", RoomOptions{Password: "s3cret"})
+	if err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if !room.HasPassword || room.Visibility != VisibilityPrivate {
+		t.Fatalf("expected a private, protected room: %+v", room)
+	}
+	if _, _, err := store.JoinRoom(room.ID, "guest"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	if _, _, err := store.JoinRoomWithPassword(room.Code, "guest", "wrong"); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	_, guest, err := store.JoinRoomWithPassword(room.Code, "guest", "s3cret")
+	if err != nil {
+		t.Fatalf("join with password failed: %v", err)
+	}
+
+	if err := store.CheckAccess(room.ID, guest.ID, ""); err != nil {
+		t.Fatalf("expected a seated player to be let in, got %v", err)
+	}
+	if err := store.CheckAccess(room.ID, "spectator", ""); !errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("expected ErrInvalidPassword, got %v", err)
+	}
+	if err := store.CheckAccess(room.ID, "spectator", "s3cret"); err != nil {
+		t.Fatalf("expected the password to let a spectator in, got %v", err)
+	}
+
+	public := VisibilityPublic
+	if _, err := store.UpdateSettings(room.ID, room.HostID, SettingsUpdate{Visibility: &public}); !errors.Is(err, ErrInvalidVisibility) {
+		t.Fatalf("expected a protected room to stay private, got %v", err)
+	}
+
+	open, _ := store.CreateRoom("other", 30)
+	if err := store.CheckAccess(open.ID, "anyone", ""); err != nil {
+		t.Fatalf("expected an open room to let anyone in, got %v", err)
+	}
+}
+
+func TestListRooms(t *testing.T) {
+	store := NewStore()
+	open, _ := store.CreateRoom("alice", 30)
+	quick, _ := game.ResolveRules(game.PresetQuick, game.RuleOverrides{})
+	full, _ := store.CreateRoomWithOptions("bob", RoomOptions{MaxPlayers: 2, Rules: quick})
+	if _, _, err := store.JoinRoom(full.ID, "guest"); err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	if _, err := store.CreateRoomWithOptions("carol", RoomOptions{Visibility: VisibilityPrivate}); err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+	if _, err := store.CreateRoomWithOptions("dave", RoomOptions{Password: "pw"}); err != nil {
+		t.Fatalf("create room failed: %v", err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	all := store.ListRooms(RoomFilter{Status: RoomWaiting}, later)
+	if len(all) != 2 {
+		t.Fatalf("expected the two public rooms, got %+v", all)
+	}
+	var bob RoomSummary
+	for _, summary := range all {
+		if summary.ID == full.ID {
+			bob = summary
+		}
+	}
+	if bob.HostName != "bob" || bob.Players != 2 || bob.MaxPlayers != 2 || bob.RulePreset != game.PresetQuick || bob.AgeSeconds < 59 {
+		t.Fatalf("unexpected summary: %+v", bob)
+	}
+
+	withSeat := store.ListRooms(RoomFilter{Status: RoomWaiting, HasSeat: true}, later)
+	if len(withSeat) != 1 || withSeat[0].ID != open.ID {
+		t.Fatalf("expected only the room with a free seat, got %+v", withSeat)
+	}
+	if playing := store.ListRooms(RoomFilter{Status: RoomPlaying}, later); len(playing) != 0 {
+		t.Fatalf("expected no playing rooms, got %+v", playing)
+	}
+}
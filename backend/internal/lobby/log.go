@@ -0,0 +1,240 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"splendor/backend/internal/game"
+)
+
+var ErrInvalidLogLimit = errors.New("invalid log limit")
+
+// DefaultLogLimit is how many log lines a room keeps unless configured.
+const DefaultLogLimit = 50
+
+const maxLogLimit = 500
+
+// LogLine is one human-readable entry of a room's game log. Clients localize
+// it from Key and Params; Text is the English rendering of the same line.
+type LogLine struct {
+	Seq    int            `json:"seq"`
+	At     time.Time      `json:"at"`
+	Key    string         `json:"key"`
+	Params map[string]any `json:"params"`
+	Text   string         `json:"text"`
+}
+
+// LogPage is a slice of a room's log in sequence order.
+type LogPage struct {
+	Lines []LogLine `json:"lines"`
+	// OldestSeq is the first line still kept; older ones have rolled off.
+	OldestSeq int  `json:"oldestSeq"`
+	HasMore   bool `json:"hasMore"`
+}
+
+func normalizeLogLimit(raw int) (int, error) {
+	if raw == 0 {
+		return DefaultLogLimit, nil
+	}
+	if raw < 1 || raw > maxLogLimit {
+		return 0, ErrInvalidLogLimit
+	}
+	return raw, nil
+}
+
+// GameLog returns up to limit log lines with a sequence number above after.
+func (s *Store) GameLog(roomRef string, after, limit int) (LogPage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return LogPage{}, ErrRoomNotFound
+	}
+	if limit <= 0 {
+		limit = DefaultLogLimit
+	}
+	limit = min(limit, maxLogLimit)
+
+	page := LogPage{Lines: make([]LogLine, 0)}
+	if len(room.Log) > 0 {
+		page.OldestSeq = room.Log[0].Seq
+	}
+	for _, line := range room.Log {
+		if line.Seq <= after {
+			continue
+		}
+		if len(page.Lines) == limit {
+			page.HasMore = true
+			break
+		}
+		page.Lines = append(page.Lines, line)
+	}
+	return page, nil
+}
+
+func (room *roomEntity) appendLog(now time.Time, key string, params map[string]any, text string) {
+	room.LogSeq++
+	room.Log = append(room.Log, LogLine{Seq: room.LogSeq, At: now, Key: key, Params: params, Text: text})
+	if over := len(room.Log) - room.LogLimit; over > 0 {
+		room.Log = append([]LogLine(nil), room.Log[over:]...)
+	}
+}
+
+func (room *roomEntity) playerName(playerID string) string {
+	for _, p := range room.Players {
+		if p.ID == playerID {
+			return p.Name
+		}
+	}
+	return playerID
+}
+
+// logMoveLocked adds the lines describing one engine move from its events.
+// Cards reserved blind are logged by tier only, since everyone reads the log.
+func logMoveLocked(room *roomEntity, playerID string, events []game.Event, timeout bool, now time.Time) {
+	name := room.playerName(playerID)
+	suffix := ""
+	if timeout {
+		suffix = " (timeout)"
+	}
+	// Every line gets its own params map; lines are shared with readers.
+	newParams := func() map[string]any {
+		params := map[string]any{"player": name}
+		if timeout {
+			params["timeout"] = true
+		}
+		return params
+	}
+
+	var bought *game.Event
+	var noble *game.Noble
+	logged := false
+	for i := range events {
+		ev := events[i]
+		switch ev.Type {
+		case game.EventTokensTaken:
+			colors := tokenColors(*ev.Tokens)
+			params := newParams()
+			params["colors"] = colors
+			room.appendLog(now, "took_tokens", params, fmt.Sprintf("%s took %s%s", name, strings.Join(colors, ", "), suffix))
+			logged = true
+		case game.EventTokensDiscarded:
+			params := newParams()
+			params["tokens"] = tokenCounts(*ev.Tokens)
+			room.appendLog(now, "returned_tokens", params, fmt.Sprintf("%s returned %s%s", name, formatTokens(*ev.Tokens), suffix))
+			logged = true
+		case game.EventTokensAdjusted:
+			params := newParams()
+			params["tokens"] = tokenCounts(*ev.Tokens)
+			room.appendLog(now, "adjusted_tokens", params, fmt.Sprintf("%s adjusted tokens by %s%s", name, formatTokens(*ev.Tokens), suffix))
+			logged = true
+		case game.EventCardReserved:
+			params := newParams()
+			text := ""
+			key := "reserved_card"
+			if ev.Card.Blind {
+				key = "reserved_card_blind"
+				params["tier"] = ev.Card.Tier
+				text = fmt.Sprintf("%s reserved a tier %d card from the deck", name, ev.Card.Tier)
+			} else {
+				params["cardId"] = ev.Card.ID
+				text = fmt.Sprintf("%s reserved %s", name, ev.Card.ID)
+			}
+			if ev.Tokens != nil && ev.Tokens.Gold > 0 {
+				params["gold"] = ev.Tokens.Gold
+				text += fmt.Sprintf(" and took %d gold", ev.Tokens.Gold)
+			}
+			room.appendLog(now, key, params, text+suffix)
+			logged = true
+		case game.EventPlayerResigned:
+			room.appendLog(now, "resigned", newParams(), fmt.Sprintf("%s resigned", name))
+			logged = true
+		case game.EventCardBought:
+			bought = &events[i]
+		case game.EventNobleClaimed:
+			noble = ev.Noble
+		}
+	}
+
+	if bought != nil {
+		params := newParams()
+		params["cardId"] = bought.Card.ID
+		params["payment"] = tokenCounts(*bought.Tokens)
+		text := fmt.Sprintf("%s bought %s", name, bought.Card.ID)
+		if bought.Tokens.Total() > 0 {
+			text += " paying " + formatTokens(*bought.Tokens)
+		}
+		key := "bought_card"
+		if noble != nil {
+			key = "bought_card_noble"
+			params["nobleId"] = noble.ID
+			text += " and received noble " + noble.ID
+		}
+		room.appendLog(now, key, params, text+suffix)
+		logged = true
+	} else if noble != nil {
+		params := newParams()
+		params["nobleId"] = noble.ID
+		room.appendLog(now, "claimed_noble", params, fmt.Sprintf("%s received noble %s%s", name, noble.ID, suffix))
+		logged = true
+	}
+	if !logged {
+		room.appendLog(now, "passed", newParams(), fmt.Sprintf("%s passed%s", name, suffix))
+	}
+
+	for _, ev := range events {
+		switch ev.Type {
+		case game.EventFinalRoundStarted:
+			room.appendLog(now, "final_round", map[string]any{"player": name},
+				fmt.Sprintf("%s reached the target score; the final round begins", name))
+		case game.EventGameFinished:
+			logGameFinishedLocked(room, ev, now)
+		}
+	}
+}
+
+func logGameFinishedLocked(room *roomEntity, ev game.Event, now time.Time) {
+	winners := make([]string, 0, len(ev.WinnerIDs))
+	for _, id := range ev.WinnerIDs {
+		winners = append(winners, room.playerName(id))
+	}
+	room.appendLog(now, "game_finished", map[string]any{"winners": winners},
+		fmt.Sprintf("Game over: %s won", strings.Join(winners, " and ")))
+}
+
+func tokenColors(tokens game.TokenSet) []string {
+	var out []string
+	for _, color := range append(append([]string(nil), game.ColoredGems...), game.GemGold) {
+		for i := 0; i < tokens.Get(color); i++ {
+			out = append(out, color)
+		}
+	}
+	return out
+}
+
+func tokenCounts(tokens game.TokenSet) map[string]int {
+	out := make(map[string]int)
+	for _, color := range append(append([]string(nil), game.ColoredGems...), game.GemGold) {
+		if n := tokens.Get(color); n != 0 {
+			out[color] = n
+		}
+	}
+	return out
+}
+
+// formatTokens renders a token set as "3 blue 1 gold".
+func formatTokens(tokens game.TokenSet) string {
+	var parts []string
+	for _, color := range append(append([]string(nil), game.ColoredGems...), game.GemGold) {
+		if n := tokens.Get(color); n != 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, color))
+		}
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, " ")
+}
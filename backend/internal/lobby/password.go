@@ -0,0 +1,92 @@
+package lobby
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var ErrInvalidPassword = errors.New("invalid room password")
+
+// Argon2id parameters for room passwords, the minimum OWASP recommends:
+// 19 MiB of memory and two passes per hash.
+const (
+	passwordSaltSize = 16
+	passwordKeySize  = 32
+	passwordTime     = 2
+	passwordMemory   = 19 * 1024
+	passwordThreads  = 1
+)
+
+// roomPassword is an Argon2id hash of a room password. The zero value means
+// the room is open. Hashing takes tens of milliseconds, so callers check a
+// password without holding the store lock.
+type roomPassword struct {
+	salt []byte
+	hash []byte
+}
+
+func newRoomPassword(password string) (roomPassword, error) {
+	if password == "" {
+		return roomPassword{}, nil
+	}
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return roomPassword{}, err
+	}
+	return roomPassword{salt: salt, hash: hashPassword(salt, password)}, nil
+}
+
+func (p roomPassword) set() bool {
+	return len(p.hash) > 0
+}
+
+// matches reports whether password opens the room; open rooms accept any.
+func (p roomPassword) matches(password string) bool {
+	if !p.set() {
+		return true
+	}
+	return subtle.ConstantTimeCompare(p.hash, hashPassword(p.salt, password)) == 1
+}
+
+func hashPassword(salt []byte, password string) []byte {
+	return argon2.IDKey([]byte(password), salt, passwordTime, passwordMemory, passwordThreads, passwordKeySize)
+}
+
+// roomPasswordOf returns the password of a room so it can be checked after
+// the store lock is released. A room's password never changes.
+func (s *Store) roomPasswordOf(roomRef string) (roomPassword, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	room, ok := s.resolveRoomLocked(roomRef)
+	if !ok {
+		return roomPassword{}, false
+	}
+	return room.Password, true
+}
+
+// CheckAccess allows playerID to read a room or follow it over WebSocket. In
+// a password-protected room only seated players, or callers with the
+// password, are let in; everyone else must not learn the seated player IDs.
+func (s *Store) CheckAccess(roomRef, playerID, password string) error {
+	s.mu.RLock()
+	room, ok := s.resolveRoomLocked(roomRef)
+	seated := ok && containsPlayer(room.Players, strings.TrimSpace(playerID))
+	var hashed roomPassword
+	if ok {
+		hashed = room.Password
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return ErrRoomNotFound
+	}
+	if seated || hashed.matches(password) {
+		return nil
+	}
+	return ErrInvalidPassword
+}
@@ -0,0 +1,130 @@
+package lobby
+
+import (
+	"errors"
+	"strings"
+
+	"splendor/backend/internal/game"
+)
+
+var (
+	ErrInvalidMaxPlayers = errors.New("invalid max players")
+	ErrInvalidVisibility = errors.New("invalid room visibility")
+)
+
+// Room visibility.
+const (
+	VisibilityPublic  = "public"
+	VisibilityPrivate = "private"
+)
+
+const minPlayers = 2
+
+// SettingsUpdate changes the settings of a waiting room. Nil fields are left
+// as they are.
+type SettingsUpdate struct {
+	TurnSeconds *int
+	Rules       *game.RuleSet
+	// RuleOverrides adjusts the room's current rules, or Rules when both are
+	// set.
+	RuleOverrides *game.RuleOverrides
+	MaxPlayers    *int
+	Visibility    *string
+	TimeoutPolicy *TimeoutPolicy
+}
+
+// UpdateSettings applies a host's settings change to a waiting room. Every
+// field is validated before any is applied. Players other than the host have
+// to confirm they are ready again under the new settings.
+// An AutoStart room the change leaves full and ready starts right away.
+func (s *Store) UpdateSettings(roomRef, hostID string, update SettingsUpdate) (*Room, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	room, err := s.hostRoomLocked(roomRef, hostID)
+	if err != nil {
+		return nil, err
+	}
+	if room.Status != RoomWaiting {
+		return nil, ErrGameAlreadyStarted
+	}
+
+	turnSeconds := room.TurnSeconds
+	if update.TurnSeconds != nil {
+		if turnSeconds, err = NormalizeTurnSeconds(*update.TurnSeconds); err != nil {
+			return nil, err
+		}
+	}
+	rules := room.Rules
+	if update.Rules != nil {
+		if rules, err = normalizeRules(*update.Rules); err != nil {
+			return nil, err
+		}
+	}
+	if update.RuleOverrides != nil {
+		if rules, err = rules.Resolve(*update.RuleOverrides); err != nil {
+			return nil, err
+		}
+	}
+	maxPlayers := room.MaxPlayers
+	if update.MaxPlayers != nil {
+		if maxPlayers, err = normalizeMaxPlayers(*update.MaxPlayers); err != nil {
+			return nil, err
+		}
+		if maxPlayers < len(room.Players) {
+			return nil, ErrInvalidMaxPlayers
+		}
+	}
+	visibility := room.Visibility
+	if update.Visibility != nil {
+		if visibility, err = normalizeVisibility(*update.Visibility); err != nil {
+			return nil, err
+		}
+		if room.Password.set() && visibility != VisibilityPrivate {
+			return nil, ErrInvalidVisibility
+		}
+	}
+	timeout := room.Timeout
+	if update.TimeoutPolicy != nil {
+		if timeout, err = normalizeTimeoutPolicy(*update.TimeoutPolicy); err != nil {
+			return nil, err
+		}
+	}
+
+	room.TurnSeconds = turnSeconds
+	room.Rules = rules
+	room.MaxPlayers = maxPlayers
+	room.Visibility = visibility
+	room.Timeout = timeout
+	for i := range room.Players {
+		if room.Players[i].ID != room.HostID && !room.Players[i].Bot {
+			room.Players[i].Ready = false
+		}
+	}
+	room.Version++
+	if err := autoStartLocked(room); err != nil {
+		return nil, err
+	}
+	return snapshotRoom(room), nil
+}
+
+func normalizeMaxPlayers(raw int) (int, error) {
+	if raw == 0 {
+		return MaxPlayers, nil
+	}
+	if raw < minPlayers || raw > MaxPlayers {
+		return 0, ErrInvalidMaxPlayers
+	}
+	return raw, nil
+}
+
+func normalizeVisibility(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", VisibilityPublic:
+		return VisibilityPublic, nil
+	case VisibilityPrivate:
+		return VisibilityPrivate, nil
+	default:
+		return "", ErrInvalidVisibility
+	}
+}
@@ -0,0 +1,346 @@
+// Package matchmaking runs the quick-match queue: players ask for a game size
+// and turn timer, and compatible tickets are grouped into a private room that
+// starts straight away.
+package matchmaking
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"splendor/backend/internal/lobby"
+)
+
+var (
+	ErrInvalidPlayers = errors.New("players must be 2, 3 or 4")
+	ErrTicketNotFound = errors.New("ticket not found")
+	ErrTicketClosed   = errors.New("ticket is no longer queued")
+)
+
+type TicketStatus string
+
+const (
+	TicketQueued    TicketStatus = "queued"
+	TicketMatched   TicketStatus = "matched"
+	TicketCancelled TicketStatus = "cancelled"
+	TicketExpired   TicketStatus = "expired"
+)
+
+const (
+	DefaultTimeout    = 2 * time.Minute
+	DefaultWidenAfter = 30 * time.Second
+
+	// closedRetention is how long a matched, cancelled or expired ticket
+	// stays readable so its holder can pick up the result.
+	closedRetention = 5 * time.Minute
+)
+
+// Config tunes the queue. Zero fields take the defaults.
+type Config struct {
+	// Timeout is how long a ticket waits before it expires.
+	Timeout time.Duration
+	// WidenAfter is the wait after which a ticket accepts any turn timer.
+	// After twice as long it also accepts games with fewer players.
+	WidenAfter time.Duration
+}
+
+// Request is what a player asks the queue for.
+type Request struct {
+	PlayerName  string
+	Players     int
+	TurnSeconds int
+}
+
+// Ticket is a player's place in the queue. RoomID, RoomCode and PlayerID are
+// set once the ticket is matched.
+type Ticket struct {
+	ID          string       `json:"id"`
+	PlayerName  string       `json:"playerName"`
+	Players     int          `json:"players"`
+	TurnSeconds int          `json:"turnSeconds"`
+	Status      TicketStatus `json:"status"`
+	QueuedAt    time.Time    `json:"queuedAt"`
+	ExpiresAt   time.Time    `json:"expiresAt"`
+	RoomID      string       `json:"roomId,omitempty"`
+	RoomCode    string       `json:"roomCode,omitempty"`
+	PlayerID    string       `json:"playerId,omitempty"`
+	ClosedAt    *time.Time   `json:"closedAt,omitempty"`
+}
+
+// Match is a room the matcher created and started.
+type Match struct {
+	Room    *lobby.Room
+	Tickets []Ticket
+}
+
+type ticketEntity struct {
+	Ticket
+	// changed is closed and replaced whenever the ticket's status changes.
+	changed chan struct{}
+}
+
+type Queue struct {
+	store *lobby.Store
+	cfg   Config
+
+	mu      sync.Mutex
+	tickets map[string]*ticketEntity
+}
+
+func NewQueue(store *lobby.Store, cfg Config) *Queue {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.WidenAfter <= 0 {
+		cfg.WidenAfter = DefaultWidenAfter
+	}
+	return &Queue{
+		store:   store,
+		cfg:     cfg,
+		tickets: make(map[string]*ticketEntity),
+	}
+}
+
+// Enqueue puts a player in the queue and returns their ticket.
+func (q *Queue) Enqueue(req Request, now time.Time) (Ticket, error) {
+	if req.Players < 2 || req.Players > 4 {
+		return Ticket{}, ErrInvalidPlayers
+	}
+	turnSeconds, err := lobby.NormalizeTurnSeconds(req.TurnSeconds)
+	if err != nil {
+		return Ticket{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now = now.UTC()
+	t := &ticketEntity{
+		Ticket: Ticket{
+			ID:          q.newTicketIDLocked(),
+			PlayerName:  strings.TrimSpace(req.PlayerName),
+			Players:     req.Players,
+			TurnSeconds: turnSeconds,
+			Status:      TicketQueued,
+			QueuedAt:    now,
+			ExpiresAt:   now.Add(q.cfg.Timeout),
+		},
+		changed: make(chan struct{}),
+	}
+	q.tickets[t.ID] = t
+	return t.Ticket, nil
+}
+
+// Cancel takes a queued ticket out of the queue.
+func (q *Queue) Cancel(ticketID string, now time.Time) (Ticket, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	t, ok := q.tickets[strings.TrimSpace(ticketID)]
+	if !ok {
+		return Ticket{}, ErrTicketNotFound
+	}
+	if t.Status != TicketQueued {
+		return Ticket{}, ErrTicketClosed
+	}
+	t.close(TicketCancelled, now)
+	return t.Ticket, nil
+}
+
+// Ticket returns the current state of a ticket, along with a channel that is
+// closed the next time its status changes.
+func (q *Queue) Ticket(ticketID string) (Ticket, <-chan struct{}, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	t, ok := q.tickets[strings.TrimSpace(ticketID)]
+	if !ok {
+		return Ticket{}, nil, ErrTicketNotFound
+	}
+	return t.Ticket, t.changed, nil
+}
+
+// Match expires overdue tickets and starts a game for every group of
+// compatible queued tickets, oldest tickets first.
+func (q *Queue) Match(now time.Time) []Match {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now = now.UTC()
+	queued := make([]*ticketEntity, 0, len(q.tickets))
+	for id, t := range q.tickets {
+		switch {
+		case t.Status != TicketQueued:
+			if now.Sub(*t.ClosedAt) >= closedRetention {
+				delete(q.tickets, id)
+			}
+		case !now.Before(t.ExpiresAt):
+			t.close(TicketExpired, now)
+		default:
+			queued = append(queued, t)
+		}
+	}
+	sort.Slice(queued, func(i, j int) bool {
+		if queued[i].QueuedAt.Equal(queued[j].QueuedAt) {
+			return queued[i].ID < queued[j].ID
+		}
+		return queued[i].QueuedAt.Before(queued[j].QueuedAt)
+	})
+
+	var matches []Match
+	taken := make(map[string]bool)
+	for _, anchor := range queued {
+		if taken[anchor.ID] {
+			continue
+		}
+		group, turnSeconds := q.groupLocked(anchor, queued, taken, now)
+		if group == nil {
+			continue
+		}
+		match, err := q.startLocked(group, turnSeconds, now)
+		if err != nil {
+			continue
+		}
+		for _, t := range group {
+			taken[t.ID] = true
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// groupLocked finds the largest game anchor accepts that enough other
+// untaken tickets also accept. Tickets that have not widened their timer must
+// all share it; the room uses that timer, or the anchor's if everyone has
+// widened.
+func (q *Queue) groupLocked(anchor *ticketEntity, queued []*ticketEntity, taken map[string]bool, now time.Time) ([]*ticketEntity, int) {
+	for size := anchor.Players; size >= q.minPlayers(anchor, now); size-- {
+		group := []*ticketEntity{anchor}
+		turnSeconds := 0
+		if !q.anyTimer(anchor, now) {
+			turnSeconds = anchor.TurnSeconds
+		}
+		for _, t := range queued {
+			if len(group) == size {
+				break
+			}
+			if t == anchor || taken[t.ID] || size > t.Players || size < q.minPlayers(t, now) {
+				continue
+			}
+			if !q.anyTimer(t, now) {
+				if turnSeconds != 0 && turnSeconds != t.TurnSeconds {
+					continue
+				}
+				turnSeconds = t.TurnSeconds
+			}
+			group = append(group, t)
+		}
+		if len(group) == size {
+			if turnSeconds == 0 {
+				turnSeconds = anchor.TurnSeconds
+			}
+			return group, turnSeconds
+		}
+	}
+	return nil, 0
+}
+
+// startLocked creates a private room for the group, seats everyone in queue
+// order and starts the game.
+func (q *Queue) startLocked(group []*ticketEntity, turnSeconds int, now time.Time) (Match, error) {
+	names := make([]string, len(group))
+	for i, t := range group {
+		names[i] = uniqueName(t.PlayerName, names[:i])
+	}
+
+	room, err := q.store.CreateRoomWithOptions(names[0], lobby.RoomOptions{
+		TurnSeconds: turnSeconds,
+		MaxPlayers:  len(group),
+		Visibility:  lobby.VisibilityPrivate,
+	})
+	if err != nil {
+		return Match{}, err
+	}
+	playerIDs := []string{room.HostID}
+	for _, name := range names[1:] {
+		_, player, err := q.store.JoinRoom(room.ID, name)
+		if err != nil {
+			_, _ = q.store.CloseRoom(room.ID, room.HostID)
+			return Match{}, err
+		}
+		playerIDs = append(playerIDs, player.ID)
+	}
+	started, err := q.store.StartGame(room.ID, room.HostID, lobby.StartOptions{Force: true})
+	if err != nil {
+		_, _ = q.store.CloseRoom(room.ID, room.HostID)
+		return Match{}, err
+	}
+
+	match := Match{Room: started}
+	for i, t := range group {
+		t.RoomID = room.ID
+		t.RoomCode = room.Code
+		t.PlayerID = playerIDs[i]
+		t.close(TicketMatched, now)
+		match.Tickets = append(match.Tickets, t.Ticket)
+	}
+	return match, nil
+}
+
+// anyTimer reports whether t has waited long enough to accept any turn timer.
+func (q *Queue) anyTimer(t *ticketEntity, now time.Time) bool {
+	return now.Sub(t.QueuedAt) >= q.cfg.WidenAfter
+}
+
+// minPlayers is the smallest game t accepts: its own size until it has waited
+// twice WidenAfter, then any game down to two players.
+func (q *Queue) minPlayers(t *ticketEntity, now time.Time) int {
+	if now.Sub(t.QueuedAt) >= 2*q.cfg.WidenAfter {
+		return 2
+	}
+	return t.Players
+}
+
+func (q *Queue) newTicketIDLocked() string {
+	for {
+		buf := make([]byte, 12)
+		if _, err := rand.Read(buf); err != nil {
+			panic(err)
+		}
+		id := hex.EncodeToString(buf)
+		if _, used := q.tickets[id]; !used {
+			return id
+		}
+	}
+}
+
+func (t *ticketEntity) close(status TicketStatus, now time.Time) {
+	closedAt := now.UTC()
+	t.Status = status
+	t.ClosedAt = &closedAt
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// uniqueName returns name, numbered if another seat already uses it.
+func uniqueName(name string, taken []string) string {
+	candidate := name
+	for n := 2; ; n++ {
+		clash := false
+		for _, other := range taken {
+			if strings.EqualFold(other, candidate) {
+				clash = true
+				break
+			}
+		}
+		if !clash {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+}
@@ -0,0 +1,131 @@
+package matchmaking
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"splendor/backend/internal/lobby"
+)
+
+func TestMatchGroupsCompatibleTickets(t *testing.T) {
+	store := lobby.NewStore()
+	queue := NewQueue(store, Config{})
+	now := time.Now()
+
+	a, err := queue.Enqueue(Request{PlayerName: "alice", Players: 3, TurnSeconds: 45}, now)
+	if err != nil {
+		t.Fatalf("enqueue failed: %v", err)
+	}
+	other, _ := queue.Enqueue(Request{PlayerName: "bob", Players: 3, TurnSeconds: 60}, now)
+	b, _ := queue.Enqueue(Request{PlayerName: "Alice", Players: 3, TurnSeconds: 45}, now.Add(time.Second))
+	if matches := queue.Match(now.Add(time.Second)); len(matches) != 0 {
+		t.Fatalf("expected no match with two compatible tickets, got %+v", matches)
+	}
+
+	c, _ := queue.Enqueue(Request{PlayerName: "carol", Players: 3, TurnSeconds: 45}, now.Add(2*time.Second))
+	_, changed, _ := queue.Ticket(a.ID)
+	matches := queue.Match(now.Add(2 * time.Second))
+	if len(matches) != 1 {
+		t.Fatalf("expected one match, got %+v", matches)
+	}
+	select {
+	case <-changed:
+	default:
+		t.Fatal("expected the ticket's change channel to close")
+	}
+
+	room := matches[0].Room
+	if room.Status != lobby.RoomPlaying || room.TurnSeconds != 45 || len(room.Players) != 3 || room.Visibility != lobby.VisibilityPrivate {
+		t.Fatalf("unexpected room: %+v", room)
+	}
+	if room.Players[1].Name != "Alice 2" {
+		t.Fatalf("expected the clashing name to be numbered, got %q", room.Players[1].Name)
+	}
+	for i, id := range []string{a.ID, b.ID, c.ID} {
+		ticket, _, err := queue.Ticket(id)
+		if err != nil {
+			t.Fatalf("ticket lookup failed: %v", err)
+		}
+		if ticket.Status != TicketMatched || ticket.RoomID != room.ID || ticket.PlayerID != room.Players[i].ID {
+			t.Fatalf("unexpected matched ticket: %+v", ticket)
+		}
+	}
+	if ticket, _, _ := queue.Ticket(other.ID); ticket.Status != TicketQueued {
+		t.Fatalf("expected bob to stay queued, got %+v", ticket)
+	}
+}
+
+func TestMatchWidensAfterWaiting(t *testing.T) {
+	store := lobby.NewStore()
+	queue := NewQueue(store, Config{WidenAfter: 10 * time.Second, Timeout: time.Minute})
+	now := time.Now()
+
+	a, _ := queue.Enqueue(Request{PlayerName: "alice", Players: 2, TurnSeconds: 30}, now)
+	b, _ := queue.Enqueue(Request{PlayerName: "bob", Players: 2, TurnSeconds: 90}, now.Add(5*time.Second))
+	if matches := queue.Match(now.Add(9 * time.Second)); len(matches) != 0 {
+		t.Fatalf("expected different timers not to match yet, got %+v", matches)
+	}
+	matches := queue.Match(now.Add(10 * time.Second))
+	if len(matches) != 1 || matches[0].Room.TurnSeconds != 90 {
+		t.Fatalf("expected alice to take bob's timer once widened, got %+v", matches)
+	}
+	for _, id := range []string{a.ID, b.ID} {
+		if ticket, _, _ := queue.Ticket(id); ticket.Status != TicketMatched {
+			t.Fatalf("expected matched ticket, got %+v", ticket)
+		}
+	}
+
+	c, _ := queue.Enqueue(Request{PlayerName: "carol", Players: 4, TurnSeconds: 30}, now)
+	d, _ := queue.Enqueue(Request{PlayerName: "dave", Players: 3, TurnSeconds: 30}, now)
+	if matches := queue.Match(now.Add(19 * time.Second)); len(matches) != 0 {
+		t.Fatalf("expected different sizes not to match yet, got %+v", matches)
+	}
+	matches = queue.Match(now.Add(20 * time.Second))
+	if len(matches) != 1 || len(matches[0].Room.Players) != 2 {
+		t.Fatalf("expected a two-player game once sizes widened, got %+v", matches)
+	}
+	for _, id := range []string{c.ID, d.ID} {
+		if ticket, _, _ := queue.Ticket(id); ticket.Status != TicketMatched {
+			t.Fatalf("expected matched ticket, got %+v", ticket)
+		}
+	}
+}
+
+func TestCancelAndExpire(t *testing.T) {
+	store := lobby.NewStore()
+	queue := NewQueue(store, Config{Timeout: time.Minute})
+	now := time.Now()
+
+	if _, err := queue.Enqueue(Request{PlayerName: "alice", Players: 5}, now); !errors.Is(err, ErrInvalidPlayers) {
+		t.Fatalf("expected ErrInvalidPlayers, got %v", err)
+	}
+	if _, err := queue.Enqueue(Request{PlayerName: "alice", Players: 2, TurnSeconds: 1}, now); !errors.Is(err, lobby.ErrInvalidTurnSeconds) {
+		t.Fatalf("expected ErrInvalidTurnSeconds, got %v", err)
+	}
+
+	a, _ := queue.Enqueue(Request{PlayerName: "alice", Players: 2}, now)
+	b, _ := queue.Enqueue(Request{PlayerName: "bob", Players: 2}, now)
+	cancelled, err := queue.Cancel(a.ID, now)
+	if err != nil || cancelled.Status != TicketCancelled {
+		t.Fatalf("cancel failed: %+v %v", cancelled, err)
+	}
+	if _, err := queue.Cancel(a.ID, now); !errors.Is(err, ErrTicketClosed) {
+		t.Fatalf("expected ErrTicketClosed, got %v", err)
+	}
+	if _, err := queue.Cancel("missing", now); !errors.Is(err, ErrTicketNotFound) {
+		t.Fatalf("expected ErrTicketNotFound, got %v", err)
+	}
+
+	if matches := queue.Match(now.Add(time.Minute)); len(matches) != 0 {
+		t.Fatalf("expected no match after expiry, got %+v", matches)
+	}
+	if ticket, _, _ := queue.Ticket(b.ID); ticket.Status != TicketExpired {
+		t.Fatalf("expected expired ticket, got %+v", ticket)
+	}
+
+	queue.Match(now.Add(time.Minute + closedRetention))
+	if _, _, err := queue.Ticket(b.ID); !errors.Is(err, ErrTicketNotFound) {
+		t.Fatalf("expected closed ticket to be dropped, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package sim plays bot-vs-bot games in-process and aggregates statistics
+// for evaluating rule variants and custom card sets.
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"splendor/backend/internal/bot"
+	"splendor/backend/internal/game"
+)
+
+var ErrInvalidConfig = errors.New("invalid simulation config")
+
+const defaultMaxMoves = 1000
+
+type Config struct {
+	Games   int
+	Players int
+	// Seed seeds the first game; game i uses Seed+i. Every bot, hard
+	// included, plays by seed and iteration count rather than the clock, so
+	// a run is reproducible regardless of Workers.
+	Seed  int64
+	Rules game.RuleSet
+	Mode  string
+	// Cards replaces the standard deck; nil plays with the standard cards.
+	Cards []game.Card
+	// Difficulties assigns a bot difficulty per seat; missing entries play
+	// normal.
+	Difficulties []string
+	// MaxMoves stops a game that has not finished, counting it as stalled.
+	// Zero means 1000.
+	MaxMoves int
+	// Workers is the number of games played in parallel; zero means one per
+	// CPU.
+	Workers int
+}
+
+// Stats aggregates a simulation run. Seat 0 moves first.
+type Stats struct {
+	Games   int `json:"games"`
+	Stalled int `json:"stalled"`
+	// SeatWins counts games each seat won; a shared win counts for every
+	// winner.
+	SeatWins           []int   `json:"seatWins"`
+	FirstPlayerWinRate float64 `json:"firstPlayerWinRate"`
+	// AverageTurns counts the turns of finished games; AverageRounds divides
+	// them by the number of players.
+	AverageTurns         float64 `json:"averageTurns"`
+	AverageRounds        float64 `json:"averageRounds"`
+	AverageWinningPoints float64 `json:"averageWinningPoints"`
+	// CardPurchases, NobleDealt and NobleClaims cover finished games only.
+	// CardPurchases has an entry for every card, bought or not.
+	CardPurchases map[string]int `json:"cardPurchases"`
+	// NobleDealt counts the games that put a noble on the table.
+	NobleDealt  map[string]int `json:"nobleDealt"`
+	NobleClaims map[string]int `json:"nobleClaims"`
+}
+
+// NobleClaimRate is the share of games dealing nobleID in which it was
+// claimed.
+func (s Stats) NobleClaimRate(nobleID string) float64 {
+	if s.NobleDealt[nobleID] == 0 {
+		return 0
+	}
+	return float64(s.NobleClaims[nobleID]) / float64(s.NobleDealt[nobleID])
+}
+
+type gameResult struct {
+	stalled       bool
+	turns         int
+	winners       []int
+	winningPoints int
+	purchases     []string
+	dealt         []string
+	claimed       []string
+}
+
+// Run plays cfg.Games games and aggregates their statistics.
+func Run(cfg Config) (Stats, error) {
+	if cfg.Games < 1 || cfg.Players < 2 || cfg.Players > 4 {
+		return Stats{}, fmt.Errorf("%w: need at least one game and 2-4 players", ErrInvalidConfig)
+	}
+	if len(cfg.Difficulties) > cfg.Players {
+		return Stats{}, fmt.Errorf("%w: %d difficulties for %d players", ErrInvalidConfig, len(cfg.Difficulties), cfg.Players)
+	}
+	if cfg.Cards != nil {
+		if err := game.ValidateCards(cfg.Cards); err != nil {
+			return Stats{}, err
+		}
+	}
+	for _, d := range cfg.Difficulties {
+		if _, err := bot.NormalizeDifficulty(d); err != nil {
+			return Stats{}, err
+		}
+	}
+	if cfg.MaxMoves <= 0 {
+		cfg.MaxMoves = defaultMaxMoves
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	results := make([]gameResult, cfg.Games)
+	errs := make([]error, cfg.Games)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i], errs[i] = playGame(cfg, cfg.Seed+int64(i))
+			}
+		}()
+	}
+	for i := 0; i < cfg.Games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return Stats{}, err
+	}
+	return aggregate(cfg, results), nil
+}
+
+func playGame(cfg Config, seed int64) (gameResult, error) {
+	seats := make([]game.Seat, cfg.Players)
+	policies := make(map[string]bot.Policy, cfg.Players)
+	index := make(map[string]int, cfg.Players)
+	for i := range seats {
+		id := fmt.Sprintf("p%d", i+1)
+		seats[i] = game.Seat{ID: id, Name: id}
+		index[id] = i
+		difficulty := ""
+		if i < len(cfg.Difficulties) {
+			difficulty = cfg.Difficulties[i]
+		}
+		policy, err := bot.New(difficulty, seed*int64(cfg.Players)+int64(i))
+		if err != nil {
+			return gameResult{}, err
+		}
+		policies[id] = policy
+	}
+
+	engine, err := game.NewWithOptions(seats, game.Options{Seed: seed, Rules: cfg.Rules, Mode: cfg.Mode, Cards: cfg.Cards})
+	if err != nil {
+		return gameResult{}, err
+	}
+	var result gameResult
+	for _, noble := range engine.Snapshot().Nobles {
+		result.dealt = append(result.dealt, noble.ID)
+	}
+
+	for moves := 0; engine.CurrentPlayerID() != ""; moves++ {
+		if moves >= cfg.MaxMoves {
+			result.stalled = true
+			return result, nil
+		}
+		current := engine.CurrentPlayerID()
+		state := engine.Snapshot()
+		if _, err := engine.Apply(current, policies[current].Choose(state.RedactedFor(current), current)); err != nil {
+			// A rejected bot move should not end the run; play the
+			// timeout move instead, as the lobby does.
+			if _, err := engine.ApplyTimeout(current); err != nil {
+				return gameResult{}, fmt.Errorf("game with seed %d: %w", seed, err)
+			}
+		}
+	}
+
+	final := engine.Snapshot()
+	result.turns = final.Turn
+	for _, id := range final.WinnerIDs {
+		result.winners = append(result.winners, index[id])
+	}
+	for _, p := range final.Players {
+		result.purchases = append(result.purchases, p.PurchasedIDs...)
+		for _, noble := range p.Nobles {
+			result.claimed = append(result.claimed, noble.ID)
+		}
+		for _, id := range final.WinnerIDs {
+			if id == p.ID {
+				result.winningPoints = p.Points
+			}
+		}
+	}
+	return result, nil
+}
+
+func aggregate(cfg Config, results []gameResult) Stats {
+	stats := Stats{
+		Games:         len(results),
+		SeatWins:      make([]int, cfg.Players),
+		CardPurchases: make(map[string]int),
+		NobleDealt:    make(map[string]int),
+		NobleClaims:   make(map[string]int),
+	}
+	cards := cfg.Cards
+	if cards == nil {
+		cards = game.Cards()
+	}
+	for _, c := range cards {
+		stats.CardPurchases[c.ID] = 0
+	}
+	finished, turns, winningPoints := 0, 0, 0
+	for _, r := range results {
+		if r.stalled {
+			stats.Stalled++
+			continue
+		}
+		for _, id := range r.dealt {
+			stats.NobleDealt[id]++
+		}
+		finished++
+		turns += r.turns
+		winningPoints += r.winningPoints
+		for _, seat := range r.winners {
+			stats.SeatWins[seat]++
+		}
+		for _, id := range r.purchases {
+			stats.CardPurchases[id]++
+		}
+		for _, id := range r.claimed {
+			stats.NobleClaims[id]++
+		}
+	}
+	if finished > 0 {
+		stats.FirstPlayerWinRate = float64(stats.SeatWins[0]) / float64(finished)
+		stats.AverageTurns = float64(turns) / float64(finished)
+		stats.AverageRounds = stats.AverageTurns / float64(cfg.Players)
+		stats.AverageWinningPoints = float64(winningPoints) / float64(finished)
+	}
+	return stats
+}
@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"splendor/backend/internal/game"
+)
+
+func TestRunAggregatesGames(t *testing.T) {
+	cfg := Config{Games: 12, Players: 3, Seed: 100, Mode: game.ModeStrict, Difficulties: []string{"easy", "normal"}}
+	stats, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	finished := stats.Games - stats.Stalled
+	if stats.Games != 12 || finished == 0 {
+		t.Fatalf("expected finished games, got %+v", stats)
+	}
+
+	wins := 0
+	for _, n := range stats.SeatWins {
+		wins += n
+	}
+	if wins < finished {
+		t.Fatalf("expected at least one winner per finished game, got %d wins for %d games", wins, finished)
+	}
+	if stats.AverageTurns <= 0 || stats.AverageRounds != stats.AverageTurns/3 || stats.AverageWinningPoints < 15 {
+		t.Fatalf("unexpected averages: %+v", stats)
+	}
+	dealt := 0
+	for id, n := range stats.NobleDealt {
+		dealt += n
+		if stats.NobleClaims[id] > n || stats.NobleClaimRate(id) > 1 {
+			t.Fatalf("noble %s claimed %d times in %d games", id, stats.NobleClaims[id], n)
+		}
+	}
+	if dealt != finished*4 {
+		t.Fatalf("expected 4 nobles dealt per finished game, got %d", dealt)
+	}
+	purchases := 0
+	for _, n := range stats.CardPurchases {
+		purchases += n
+	}
+	if purchases == 0 || len(stats.CardPurchases) != len(game.Cards()) {
+		t.Fatalf("expected purchases listed for every card, got %d cards and %d purchases", len(stats.CardPurchases), purchases)
+	}
+
+	cfg.Workers = 1
+	again, err := Run(cfg)
+	if err != nil {
+		t.Fatalf("rerun failed: %v", err)
+	}
+	if !reflect.DeepEqual(again, stats) {
+		t.Fatal("expected the same stats for the same seed regardless of workers")
+	}
+}
+
+func TestRunWithCustomCards(t *testing.T) {
+	var cards []game.Card
+	for _, c := range game.Cards() {
+		if c.Bonus != game.GemBlack {
+			cards = append(cards, c)
+		}
+	}
+	stats, err := Run(Config{Games: 4, Players: 2, Seed: 7, Mode: game.ModeStrict, Cards: cards})
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(stats.CardPurchases) != len(cards) {
+		t.Fatalf("expected purchases listed for the %d custom cards, got %d", len(cards), len(stats.CardPurchases))
+	}
+	for id := range stats.CardPurchases {
+		if strings.Contains(id, game.GemBlack) {
+			t.Fatalf("card %s is not in the custom set", id)
+		}
+	}
+}
+
+func TestRunRejectsBadConfig(t *testing.T) {
+	if _, err := Run(Config{Games: 1, Players: 5}); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if _, err := Run(Config{Games: 1, Players: 2, Difficulties: []string{"godlike"}}); err == nil {
+		t.Fatal("expected unknown difficulty to be rejected")
+	}
+	if _, err := Run(Config{Games: 1, Players: 2, Cards: []game.Card{{ID: "x", Tier: 4}}}); !errors.Is(err, game.ErrInvalidCardSet) {
+		t.Fatalf("expected ErrInvalidCardSet, got %v", err)
+	}
+}
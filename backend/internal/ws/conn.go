@@ -0,0 +1,38 @@
+package ws
+
+import (
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Conn wraps a websocket connection so the hub and the connection's own
+// handler can both write to it: gorilla allows only one writer at a time.
+type Conn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func NewConn(conn *websocket.Conn) *Conn {
+	return &Conn{conn: conn}
+}
+
+func (c *Conn) WriteJSON(v any) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// ReadJSON and ReadMessage must only be called from one goroutine, the
+// connection's handler.
+func (c *Conn) ReadJSON(v any) error {
+	return c.conn.ReadJSON(v)
+}
+
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	return c.conn.ReadMessage()
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
@@ -1,31 +1,33 @@
 package ws
 
-import (
-	"sync"
+import "sync"
 
-	"github.com/gorilla/websocket"
-)
+// Client describes who is behind a connection and what extras they asked for.
+type Client struct {
+	PlayerID     string
+	LegalActions bool
+}
 
 type Hub struct {
 	mu       sync.RWMutex
-	byRoomID map[string]map[*websocket.Conn]struct{}
+	byRoomID map[string]map[*Conn]Client
 }
 
 func NewHub() *Hub {
-	return &Hub{byRoomID: make(map[string]map[*websocket.Conn]struct{})}
+	return &Hub{byRoomID: make(map[string]map[*Conn]Client)}
 }
 
-func (h *Hub) Add(roomID string, conn *websocket.Conn) {
+func (h *Hub) Add(roomID string, conn *Conn, client Client) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
 	if _, ok := h.byRoomID[roomID]; !ok {
-		h.byRoomID[roomID] = make(map[*websocket.Conn]struct{})
+		h.byRoomID[roomID] = make(map[*Conn]Client)
 	}
-	h.byRoomID[roomID][conn] = struct{}{}
+	h.byRoomID[roomID][conn] = client
 }
 
-func (h *Hub) Remove(roomID string, conn *websocket.Conn) {
+func (h *Hub) Remove(roomID string, conn *Conn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -39,19 +41,58 @@ func (h *Hub) Remove(roomID string, conn *websocket.Conn) {
 	}
 }
 
+// Disconnect sends payload to every connection playerID has open in the room
+// and closes them. A nil payload closes without a message.
+func (h *Hub) Disconnect(roomID, playerID string, payload any) {
+	h.mu.Lock()
+	var targets []*Conn
+	for conn, client := range h.byRoomID[roomID] {
+		if client.PlayerID == playerID {
+			targets = append(targets, conn)
+			delete(h.byRoomID[roomID], conn)
+		}
+	}
+	if len(h.byRoomID[roomID]) == 0 {
+		delete(h.byRoomID, roomID)
+	}
+	h.mu.Unlock()
+
+	for _, conn := range targets {
+		if payload != nil {
+			_ = conn.WriteJSON(payload)
+		}
+		_ = conn.Close()
+	}
+}
+
 func (h *Hub) Broadcast(roomID string, payload any) {
+	h.BroadcastEach(roomID, func(Client) any { return payload })
+}
+
+// BroadcastEach sends every connection in the room its own payload built by
+// build. A nil payload skips that connection.
+func (h *Hub) BroadcastEach(roomID string, build func(Client) any) {
+	type target struct {
+		conn   *Conn
+		client Client
+	}
+
 	h.mu.RLock()
 	conns := h.byRoomID[roomID]
-	targets := make([]*websocket.Conn, 0, len(conns))
-	for conn := range conns {
-		targets = append(targets, conn)
+	targets := make([]target, 0, len(conns))
+	for conn, client := range conns {
+		targets = append(targets, target{conn: conn, client: client})
 	}
 	h.mu.RUnlock()
 
-	for _, conn := range targets {
-		if err := conn.WriteJSON(payload); err != nil {
-			_ = conn.Close()
-			h.Remove(roomID, conn)
+	for _, t := range targets {
+		payload := build(t.client)
+		if payload == nil {
+			continue
+		}
+		if err := t.conn.WriteJSON(payload); err != nil {
+			_ = t.conn.Close()
+			h.Remove(roomID, t.conn)
 		}
 	}
 }
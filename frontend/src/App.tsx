@@ -1,7 +1,7 @@
 import { useEffect, useMemo, useRef, useState } from "react";
 import type { CSSProperties } from "react";
 import { AnimatePresence, motion } from "framer-motion";
-import { applyAction, buildWsUrl, createRoom, joinRoom, loadRoom, startGame } from "./api";
+import { applyAction, buildWsUrl, createRoom, joinRoom, loadRoom, setReady, startGame } from "./api";
 import type { Card, GameAction, Noble, PlayerState, Room, WsActionErrorMessage, WsSnapshotMessage } from "./types";
 import "./App.css";
 
@@ -80,6 +80,7 @@ export default function App() {
     black: 0,
     gold: 0
   });
+  const [discardDraft, setDiscardDraft] = useState<Record<string, number>>({});
   const [stageScale, setStageScale] = useState(1);
   const [turnCountdown, setTurnCountdown] = useState(0);
   const wsRef = useRef<WebSocket | null>(null);
@@ -96,6 +97,17 @@ export default function App() {
     return room.status === "waiting" && room.players.length >= 2;
   }, [room]);
 
+  // Everyone but the host must be ready unless the host forces the start.
+  const allReady = useMemo(() => {
+    if (!room) return false;
+    return room.players.every((p) => p.id === room.hostId || p.ready);
+  }, [room]);
+
+  const isReady = useMemo(() => {
+    if (!room || !session) return false;
+    return room.players.some((p) => p.id === session.playerId && p.ready);
+  }, [room, session]);
+
   const currentPlayerName = useMemo(() => {
     if (!room?.game) return "-";
     const current = (room.game.players ?? []).find((p) => p.id === room.game?.currentPlayerId);
@@ -107,6 +119,30 @@ export default function App() {
     return (room.game.players ?? []).find((p) => p.id === session.playerId) ?? null;
   }, [room, session]);
 
+  // Reserve limit of the room's rule set; 3 in the standard game.
+  const reserveLimit = room?.game?.rules?.reserveLimit ?? 3;
+  const canReserve = (myPlayerState?.reserved ?? []).length < reserveLimit;
+
+  // The discard this player owes before their turn can end, if any.
+  const pendingDiscard = useMemo(() => {
+    const pending = room?.game?.pending;
+    if (!pending || !session || pending.action !== "discard_tokens" || pending.playerId !== session.playerId) return 0;
+    return pending.discardCount ?? 0;
+  }, [room, session]);
+
+  // The nobles this player must choose between, when several visit at once.
+  const pendingNobles = useMemo(() => {
+    const pending = room?.game?.pending;
+    if (!pending || !session || pending.action !== "claim_noble" || pending.playerId !== session.playerId) return [];
+    const ids = pending.nobleIds ?? [];
+    return (room?.game?.nobles ?? []).filter((noble) => ids.includes(noble.id));
+  }, [room, session]);
+
+  const discardSelected = useMemo(
+    () => TOKEN_ACTION_COLORS.reduce((sum, color) => sum + (discardDraft[color] ?? 0), 0),
+    [discardDraft]
+  );
+
   function appendLog(message: string) {
     setEventLog((prev) => {
       const next = [`${new Date().toLocaleTimeString([], { hour12: false })} ${message}`, ...prev];
@@ -199,6 +235,10 @@ export default function App() {
     roomRef.current = room;
   }, [room]);
 
+  useEffect(() => {
+    setDiscardDraft({});
+  }, [pendingDiscard]);
+
   useEffect(() => {
     try {
       const raw = localStorage.getItem(SESSION_STORAGE_KEY);
@@ -225,7 +265,7 @@ export default function App() {
     if (!session) return;
     void (async () => {
       try {
-        const latest = await loadRoom(session.roomId);
+        const latest = await loadRoom(session.roomId, session.playerId);
         setRoom(latest);
       } catch {
         setSession(null);
@@ -275,6 +315,10 @@ export default function App() {
           setStatusText(`Action rejected: ${err.error}`);
           appendLog(`Action rejected: ${err.error}`);
           pushErrorToast(`Action rejected: ${err.error}`);
+        } else if (raw.type === "kicked") {
+          goHome();
+          setStatusText("You were removed from the room by the host");
+          pushErrorToast("You were removed from the room by the host");
         }
       } catch {
         setStatusText("Received unknown WS payload");
@@ -328,10 +372,10 @@ export default function App() {
     }
   }
 
-  async function onStartGame() {
+  async function onStartGame(force = false) {
     if (!session || !room) return;
     try {
-      const updated = await startGame(session.roomId, session.playerId);
+      const updated = await startGame(session.roomId, session.playerId, force);
       setRoom(updated);
       setStatusText("Game started");
       appendLog("Game started");
@@ -341,10 +385,22 @@ export default function App() {
     }
   }
 
+  async function onToggleReady() {
+    if (!session || !room) return;
+    try {
+      const updated = await setReady(session.roomId, session.playerId, !isReady);
+      setRoom(updated);
+      setStatusText(isReady ? "Not ready" : "Ready");
+    } catch (err) {
+      setStatusText(String(err));
+      appendLog(`Ready failed: ${String(err)}`);
+    }
+  }
+
   async function onRefreshRoom() {
     if (!session) return;
     try {
-      const latest = await loadRoom(session.roomId);
+      const latest = await loadRoom(session.roomId, session.playerId);
       setRoom(latest);
       setStatusText("Room refreshed");
       appendLog("Room refreshed");
@@ -375,7 +431,7 @@ export default function App() {
       const current = prev[color] ?? 0;
       const bank = room?.game?.bank[color as keyof typeof room.game.bank] ?? 0;
       const owned = myPlayerState?.tokens[color as keyof typeof myPlayerState.tokens] ?? 0;
-      const min = Math.max(-2, -owned);
+      const min = -owned;
       const max = Math.min(2, bank);
       const next = Math.max(min, Math.min(max, current + delta));
       return { ...prev, [color]: next };
@@ -424,6 +480,28 @@ export default function App() {
     }
   }
 
+  function adjustDiscardDraft(color: string, delta: number) {
+    setDiscardDraft((prev) => {
+      const owned = myPlayerState?.tokens[color as keyof typeof myPlayerState.tokens] ?? 0;
+      const next = Math.max(0, Math.min(owned, (prev[color] ?? 0) + delta));
+      return { ...prev, [color]: next };
+    });
+  }
+
+  async function submitDiscard() {
+    const colors: string[] = [];
+    for (const color of TOKEN_ACTION_COLORS) {
+      for (let i = 0; i < (discardDraft[color] ?? 0); i++) colors.push(color);
+    }
+    if (colors.length !== pendingDiscard) {
+      setStatusText(`Select exactly ${pendingDiscard} tokens to return`);
+      return;
+    }
+    if (await submitAction({ type: "discard_tokens", payload: { colors } })) {
+      setDiscardDraft({});
+    }
+  }
+
   function renderNoble(noble: Noble) {
     const reqs = tokenParts(noble.requirement);
     return (
@@ -482,7 +560,7 @@ export default function App() {
             <button disabled={Boolean(owner) && !canBuyReserved} onClick={() => submitAction(buyAction)}>
               Buy
             </button>
-            <button disabled={Boolean(owner) || readonly} onClick={() => submitAction({ type: "reserve_card", payload: { cardId: card.id } })}>
+            <button disabled={Boolean(owner) || readonly || !canReserve} onClick={() => submitAction({ type: "reserve_card", payload: { cardId: card.id } })}>
               Reserve
             </button>
           </div>
@@ -491,7 +569,38 @@ export default function App() {
     );
   }
 
-  function renderReservedMini(card: Card, ownerId: string) {
+  // The host's start controls: a plain start once everyone is ready, and an
+  // explicit force start while someone is not.
+  function renderStartButtons() {
+    return (
+      <>
+        <button
+          onClick={() => onStartGame()}
+          disabled={!canStart || !allReady}
+          title={!canStart ? "Need at least 2 players" : allReady ? "Start game" : "Waiting for players to get ready"}
+        >
+          Start Game
+        </button>
+        {canStart && !allReady && (
+          <button onClick={() => onStartGame(true)} title="Start without waiting for everyone to be ready">
+            Force Start
+          </button>
+        )}
+      </>
+    );
+  }
+
+  function renderReservedMini(card: Card, ownerId: string, index: number) {
+    if (card.blind && ownerId !== session?.playerId) {
+      return (
+        <div key={`mini-${ownerId}-blind-${index}`} className="reserved-mini reserved-mini-blind" title="Reserved face down">
+          <div className="reserved-mini-head">
+            <span>Tier {card.tier}</span>
+          </div>
+          <p className="reserved-mini-back">Face down</p>
+        </div>
+      );
+    }
     const costs = tokenParts(card.cost);
     const canBuy = ownerId === session?.playerId;
     return (
@@ -571,14 +680,13 @@ export default function App() {
                     <p>Live: {statusText}</p>
                     <div className="room-actions">
                       <button onClick={onRefreshRoom}>Refresh</button>
-                      {isHost && room?.status === "waiting" && (
-                        <button onClick={onStartGame} disabled={!canStart} title={canStart ? "Start game" : "Need at least 2 players"}>
-                          Start
-                        </button>
-                      )}
+                      {isHost && room?.status === "waiting" && renderStartButtons()}
                       <button onClick={goHome}>Back Home</button>
                     </div>
                     {room?.status === "waiting" && room.players.length < 2 && <p className="hint">Need at least 2 players to start.</p>}
+                    {isHost && room?.status === "waiting" && canStart && !allReady && (
+                      <p className="hint">Waiting for players to get ready; use Force Start to begin anyway.</p>
+                    )}
                   </article>
 
                   <article className="panel log-panel">
@@ -628,32 +736,77 @@ export default function App() {
                       {room.game.finalRound && <span className="final-round-inline">Final round: {room.game.finalTurnsLeft}</span>}
                     </div>
 
-                    <div className="token-picker">
-                      {TOKEN_ACTION_COLORS.map((color) => (
-                        <div key={`picker-${color}`} className="token-picker-item">
-                          <div className={`token-coin ${color}`}>
-                            <span className="token-coin-badge">{room.game?.bank[color as keyof typeof room.game.bank] ?? 0}</span>
+                    {pendingNobles.length > 0 ? (
+                      <div className="pending-decision">
+                        <h4>Several nobles visit you: choose one</h4>
+                        <div className="nobles">
+                          {pendingNobles.map((noble) => (
+                            <button
+                              key={`claim-${noble.id}`}
+                              className="noble-choice"
+                              onClick={() => void submitAction({ type: "claim_noble", payload: { nobleId: noble.id } })}
+                            >
+                              {renderNoble(noble)}
+                            </button>
+                          ))}
+                        </div>
+                      </div>
+                    ) : pendingDiscard > 0 ? (
+                      <div className="pending-decision">
+                        <h4>
+                          Over the token limit: return {pendingDiscard} ({discardSelected} selected)
+                        </h4>
+                        <div className="token-picker">
+                          {TOKEN_ACTION_COLORS.map((color) => (
+                            <div key={`discard-${color}`} className="token-picker-item">
+                              <div className={`token-coin ${color}`}>
+                                <span className="token-coin-badge">
+                                  {myPlayerState?.tokens[color as keyof typeof myPlayerState.tokens] ?? 0}
+                                </span>
+                              </div>
+                              <div className="token-adjust">
+                                <button onClick={() => adjustDiscardDraft(color, -1)}>−</button>
+                                <span>{discardDraft[color] ?? 0}</span>
+                                <button onClick={() => adjustDiscardDraft(color, 1)}>+</button>
+                              </div>
+                            </div>
+                          ))}
+                          <div className="token-picker-actions">
+                            <button disabled={discardSelected !== pendingDiscard} onClick={() => void submitDiscard()}>
+                              Return Tokens
+                            </button>
+                            <button onClick={() => setDiscardDraft({})}>Clear</button>
                           </div>
-                          {color !== "gold" && (
-                            <div className="token-adjust">
-                              <button onClick={() => adjustTokenDraft(color, -1)}>−</button>
-                              <span>{tokenDraft[color] ?? 0}</span>
-                              <button onClick={() => adjustTokenDraft(color, 1)}>+</button>
+                        </div>
+                      </div>
+                    ) : (
+                      <div className="token-picker">
+                        {TOKEN_ACTION_COLORS.map((color) => (
+                          <div key={`picker-${color}`} className="token-picker-item">
+                            <div className={`token-coin ${color}`}>
+                              <span className="token-coin-badge">{room.game?.bank[color as keyof typeof room.game.bank] ?? 0}</span>
                             </div>
-                          )}
-                          {color === "gold" && <div className="token-adjust-placeholder" />}
+                            {color !== "gold" && (
+                              <div className="token-adjust">
+                                <button onClick={() => adjustTokenDraft(color, -1)}>−</button>
+                                <span>{tokenDraft[color] ?? 0}</span>
+                                <button onClick={() => adjustTokenDraft(color, 1)}>+</button>
+                              </div>
+                            )}
+                            {color === "gold" && <div className="token-adjust-placeholder" />}
+                          </div>
+                        ))}
+                        <div className="token-picker-actions">
+                          <button onClick={() => void submitTokenDraft()}>Submit Selection</button>
+                          <button
+                            onClick={() => setTokenDraft({ white: 0, blue: 0, green: 0, red: 0, black: 0, gold: 0 })}
+                          >
+                            Clear
+                          </button>
+                          <button onClick={() => void submitAction({ type: "pass" })}>Pass</button>
                         </div>
-                      ))}
-                      <div className="token-picker-actions">
-                        <button onClick={() => void submitTokenDraft()}>Submit Selection</button>
-                        <button
-                          onClick={() => setTokenDraft({ white: 0, blue: 0, green: 0, red: 0, black: 0, gold: 0 })}
-                        >
-                          Clear
-                        </button>
-                        <button onClick={() => void submitAction({ type: "pass" })}>Pass</button>
                       </div>
-                    </div>
+                    )}
                   </article>
 
                   <article className="panel players-panel">
@@ -687,9 +840,9 @@ export default function App() {
                               </div>
                             </div>
                             <aside className="player-reserved-side">
-                              <p className="reserved-side-title">Reserved {(player.reserved ?? []).length}/3</p>
+                              <p className="reserved-side-title">Reserved {(player.reserved ?? []).length}/{reserveLimit}</p>
                               {(player.reserved ?? []).length > 0 ? (
-                                <div className="reserved-mini-list">{(player.reserved ?? []).map((card) => renderReservedMini(card, player.id))}</div>
+                                <div className="reserved-mini-list">{(player.reserved ?? []).map((card, index) => renderReservedMini(card, player.id, index))}</div>
                               ) : (
                                 <p className="reserved-empty-inline">None</p>
                               )}
@@ -708,10 +861,9 @@ export default function App() {
                 <p>Turn Seconds: {room.turnSeconds}</p>
                 <div className="room-actions">
                   <button onClick={onRefreshRoom}>Refresh</button>
-                  {isHost && room?.status === "waiting" && (
-                    <button onClick={onStartGame} disabled={!canStart} title={canStart ? "Start game" : "Need at least 2 players"}>
-                      Start Game
-                    </button>
+                  {isHost && room?.status === "waiting" && renderStartButtons()}
+                  {!isHost && room?.status === "waiting" && (
+                    <button onClick={onToggleReady}>{isReady ? "Not Ready" : "Ready"}</button>
                   )}
                   <button onClick={goHome}>Back Home</button>
                 </div>
@@ -720,6 +872,7 @@ export default function App() {
                   {room?.players.map((p) => (
                     <span className="player-chip" key={p.id}>
                       {p.name}
+                      {p.id === room.hostId ? " (host)" : p.ready ? " (ready)" : ""}
                     </span>
                   ))}
                 </div>
@@ -68,15 +68,23 @@ export function joinRoom(roomId: string, playerName: string): Promise<JoinRoomRe
   });
 }
 
-export function startGame(roomId: string, playerId: string): Promise<Room> {
+export function startGame(roomId: string, playerId: string, force = false): Promise<Room> {
   return request<Room>(`/api/rooms/${roomId}/start`, {
     method: "POST",
-    body: JSON.stringify({ playerId })
+    body: JSON.stringify({ playerId, force })
   });
 }
 
-export function loadRoom(roomId: string): Promise<Room> {
-  return request<Room>(`/api/rooms/${roomId}`);
+export function setReady(roomId: string, playerId: string, ready: boolean): Promise<Room> {
+  return request<Room>(`/api/rooms/${roomId}/ready`, {
+    method: "POST",
+    body: JSON.stringify({ playerId, ready })
+  });
+}
+
+export function loadRoom(roomId: string, playerId: string): Promise<Room> {
+  const query = new URLSearchParams({ playerId });
+  return request<Room>(`/api/rooms/${roomId}?${query.toString()}`);
 }
 
 export function applyAction(roomId: string, playerId: string, action: GameAction): Promise<Room> {
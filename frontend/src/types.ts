@@ -18,6 +18,9 @@ export type Card = {
   bonus: "white" | "blue" | "green" | "red" | "black";
   points: number;
   cost: TokenSet;
+  // Set on cards reserved face down from a deck. Other players only get the
+  // tier of such a card; its id, bonus and cost are empty.
+  blind?: boolean;
 };
 
 export type Noble = {
@@ -29,6 +32,7 @@ export type Noble = {
 export type Player = {
   id: string;
   name: string;
+  ready: boolean;
 };
 
 export type PlayerState = {
@@ -44,6 +48,16 @@ export type PlayerState = {
   lastAction: string;
 };
 
+export type RuleSet = {
+  preset: string;
+  targetScore: number;
+  tokenLimit: number;
+  reserveLimit: number;
+  goldCount: number;
+  bankTokens: [number, number, number];
+  extraNobles: number;
+};
+
 export type GameState = {
   status: "playing" | "finished";
   turn: number;
@@ -60,6 +74,18 @@ export type GameState = {
   winnerIds: string[];
   finalRound: boolean;
   finalTurnsLeft: number;
+  phase: "action" | "awaiting_discard" | "choose_noble";
+  pending?: Pending;
+  rules: RuleSet;
+};
+
+// Pending is a decision the current player must settle before the turn ends;
+// only pending.action is accepted until then.
+export type Pending = {
+  playerId: string;
+  action: "discard_tokens" | "claim_noble";
+  discardCount?: number;
+  nobleIds?: string[];
 };
 
 export type Room = {
@@ -81,10 +107,11 @@ export type ActionPayload = {
   adjust?: Record<string, number>;
   cardId?: string;
   source?: "tableau" | "reserved";
+  nobleId?: string;
 };
 
 export type GameAction = {
-  type: "take_tokens" | "discard_tokens" | "adjust_tokens" | "reserve_card" | "buy_card" | "pass";
+  type: "take_tokens" | "discard_tokens" | "adjust_tokens" | "reserve_card" | "buy_card" | "claim_noble" | "pass";
   payload?: ActionPayload;
 };
 